@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelrestful
+
+import (
+	"go.opentelemetry.io/otel/label"
+)
+
+// Server HTTP metrics
+const (
+	ServerLatency  = "http.server.duration"    // Incoming end to end duration, microseconds
+	FilterOverhead = "restful.filter.overhead" // Time spent in OTelFilter itself, outside of the rest of the filter chain, microseconds, see WithFilterOverhead
+)
+
+// Attribute keys that can be added to a span.
+const (
+	RequestAcceptKey       = label.Key("http.request.header.accept")        // the Accept header of the request
+	RequestContentTypeKey  = label.Key("http.request.header.content_type")  // the Content-Type header of the request
+	ResponseContentTypeKey = label.Key("http.response.header.content_type") // the Content-Type header of the response
+	ResponseLocationKey    = label.Key("http.response.header.location")     // the Location header of a redirect response, see WithRedirectLocation
+	RouteProducesKey       = label.Key("http.route.produces")               // the media types the selected route can produce, see WithContainer
+	RouteConsumesKey       = label.Key("http.route.consumes")               // the media types the selected route can consume, see WithContainer
+	WebServiceKey          = label.Key("http.server.webservice")            // the root path of the WebService of the selected route, see WithContainer
+	NegotiationFailureKey  = label.Key("restful.negotiation_failure")       // why go-restful selected no route for a request, one of the NegotiationFailure values, only seen by container filters
+	RouteOperationKey      = label.Key("restful.operation")                 // the operation name of the selected route, see WithOperationSpanNames
+
+	FilterChainDurationKey = label.Key("restful.filter_chain.duration") // the time spent in the filter chain after OTelFilter in microseconds, see WithFilterChainDuration
+	EntityMediaTypeKey     = label.Key("restful.entity.media_type")     // the media type of a request body that could not be parsed, see ReadEntity
+
+	RouteParamCountKey       = label.Key("restful.route.param_count")       // the number of path parameters of the selected route, see WithRouteParamAttributes
+	RouteWildcardKey         = label.Key("restful.route.wildcard")          // whether the selected route has a wildcard path parameter, like {subpath:*}, see WithRouteParamAttributes
+	RouteWildcardSegmentsKey = label.Key("restful.route.wildcard.segments") // the number of path segments the wildcard parameter matched, at most 32, see WithRouteParamAttributes
+
+	FilterIndexKey = label.Key("restful.filter.index") // the index of OTelFilter in the filter chain it runs in, counted from 0, see WithDebug
+	FilterCountKey = label.Key("restful.filter.count") // the number of filters of the filter chain OTelFilter runs in, see WithDebug
+)
+
+// Values of the NegotiationFailureKey attribute.
+const (
+	NegotiationFailureAccept      = "accept"       // no route produces a media type the Accept header of the request allows, answered with 406 Not Acceptable
+	NegotiationFailureContentType = "content_type" // no route consumes the Content-Type of the request, answered with 415 Unsupported Media Type
+)
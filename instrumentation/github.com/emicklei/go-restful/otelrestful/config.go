@@ -15,6 +15,13 @@
 package otelrestful
 
 import (
+	"context"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
@@ -22,7 +29,25 @@ import (
 // config is used to configure the go-restful middleware.
 type config struct {
 	TracerProvider oteltrace.TracerProvider
+	MeterProvider  metric.MeterProvider
 	Propagators    propagation.TextMapPropagator
+	Container      *restful.Container
+
+	FilterChainDuration        bool
+	FilterOverhead             bool
+	WebServiceMetricLabel      bool
+	TrailingSlashNormalization bool
+	OperationSpanNames         bool
+	SortedLabels               bool
+	MethodOverrideHeader       string
+	RedirectLocation           bool
+	TrustForwardedHeaders      bool
+	RouteParamAttributes       bool
+	AuthSchemeAttribute        bool
+	Debug                      bool
+	ContextAttributeExtractor  func(context.Context) []label.KeyValue
+	SpanAttributes             []label.KeyValue
+	ObservationRecorders       map[string]metric.Float64ValueRecorder
 }
 
 // Option specifies instrumentation configuration options.
@@ -44,3 +69,212 @@ func WithTracerProvider(provider oteltrace.TracerProvider) Option {
 		cfg.TracerProvider = provider
 	}
 }
+
+// WithMeterProvider specifies a meter provider to use for creating a meter.
+// If none is specified, the global provider is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(cfg *config) {
+		cfg.MeterProvider = provider
+	}
+}
+
+// WithContainer specifies the container the filter is installed in. It is
+// used to look up the route a request was dispatched to so that route
+// metadata, like the media types it produces and consumes and the root path
+// of its WebService, can be recorded. If none is specified, or no route
+// matches the request, no route metadata is recorded.
+func WithContainer(container *restful.Container) Option {
+	return func(cfg *config) {
+		cfg.Container = container
+	}
+}
+
+// WithFilterChainDuration specifies whether to record the time spent in the
+// rest of the filter chain, that is the filters following OTelFilter and the
+// route function, with the FilterChainDurationKey span attribute. Compared
+// to the duration of the span, it leaves out the work of OTelFilter itself.
+func WithFilterChainDuration(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.FilterChainDuration = enabled
+	}
+}
+
+// WithFilterOverhead specifies whether to record the time spent in
+// OTelFilter itself with the FilterOverhead metric, that is extracting the
+// propagated context, starting and ending the span and recording the
+// metrics, but not the rest of the filter chain. It is recorded with the
+// same labels as the ServerLatency metric, so that the overhead of the
+// instrumentation can be compared with the duration of the requests. It is
+// disabled by default.
+func WithFilterOverhead(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.FilterOverhead = enabled
+	}
+}
+
+// WithContextAttributeExtractor specifies a function returning attributes
+// for the context of a request, which are added to its span when it is
+// started. It allows the fields a structured logger carries in the request
+// context to also be found on the span. The function may return nil if the
+// context carries no fields.
+func WithContextAttributeExtractor(extractor func(context.Context) []label.KeyValue) Option {
+	return func(cfg *config) {
+		cfg.ContextAttributeExtractor = extractor
+	}
+}
+
+// WithWebServiceMetricLabel specifies whether to label the ServerLatency
+// metric with the WebServiceKey label, the root path of the WebService of
+// the selected route, to group requests by API group or version. It has no
+// effect unless WithContainer is used. Requests matching no route are not
+// labeled.
+func WithWebServiceMetricLabel(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.WebServiceMetricLabel = enabled
+	}
+}
+
+// WithTrailingSlashNormalization specifies whether to remove the trailing
+// slash of the route of requests, so that "/users" and "/users/" are
+// reported as the same route in span names, the http.route attribute and
+// metric labels, instead of creating duplicate series. The root route "/" is
+// kept. It is disabled by default. If both forms are registered as distinct
+// routes, they are then reported as one, so their telemetry can no longer be
+// told apart.
+func WithTrailingSlashNormalization(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.TrailingSlashNormalization = enabled
+	}
+}
+
+// WithResourceAttributesFromEnv adds the attributes otelhttp.EnvAttributes
+// returns for prefix, like "OTEL_HTTP_ATTR_", to all the spans of the
+// filter, to tag them with deployment details like the region or version
+// without code changes. The environment is read once, when the filter is
+// created.
+func WithResourceAttributesFromEnv(prefix string) Option {
+	return func(cfg *config) {
+		cfg.SpanAttributes = append(cfg.SpanAttributes, otelhttp.EnvAttributes(prefix)...)
+	}
+}
+
+// WithOperationSpanNames specifies whether to name the spans of requests
+// after the operation of the selected route, set with
+// restful.RouteBuilder.Operation, and record it with the RouteOperationKey
+// attribute, instead of naming them after the route path. Operation names
+// are stable across path changes, which suits generated API servers. Spans
+// of routes without an operation, including those go-restful named after
+// their route function, are still named after the path. It has no effect
+// unless WithContainer is used.
+func WithOperationSpanNames(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.OperationSpanNames = enabled
+	}
+}
+
+// WithSortedLabels specifies whether to sort the labels of the ServerLatency
+// metric by key. Without it, labels are in the order they are built in,
+// which depends on the options used and on the request. Sorted labels suit
+// metric backends sensitive to label order, or deduplicating series by their
+// labels. It is disabled by default.
+func WithSortedLabels(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.SortedLabels = enabled
+	}
+}
+
+// WithMethodOverrideHeader specifies the name of the header, like
+// X-HTTP-Method-Override, POST requests override their method with, as
+// with the otelhttp.WithMethodOverrideHeader option of the otelhttp
+// Handler. The overriding method is recorded with the http.method attribute
+// and, for all requests, with the http.method label of the ServerLatency
+// metric, and the method the request was sent with with the
+// otelhttp.RequestMethodOriginalKey attribute. go-restful still routes
+// requests by the method they were sent with. It is disabled by default.
+func WithMethodOverrideHeader(header string) Option {
+	return func(cfg *config) {
+		cfg.MethodOverrideHeader = header
+	}
+}
+
+// WithRedirectLocation specifies whether to record the Location header of
+// responses with a 3xx status, like those of routes redirecting requests
+// to another path, with the ResponseLocationKey attribute, so that the
+// redirect chain of a request can be followed across its spans. The header
+// is recorded as it is, including its query string. It is disabled by
+// default.
+func WithRedirectLocation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.RedirectLocation = enabled
+	}
+}
+
+// WithTrustForwardedHeaders specifies whether to record the scheme the
+// client used, as reported by the Forwarded or X-Forwarded-Proto header of
+// requests, see otelhttp.ForwardedScheme, with the http.scheme attribute and
+// the label of the ServerLatency metric, like the
+// otelhttp.WithTrustForwardedHeaders option of the otelhttp Handler. It
+// suits services behind a proxy terminating TLS. Clients can set these
+// headers themselves, so it must only be enabled if all requests go through
+// proxies overwriting them. It is disabled by default.
+func WithTrustForwardedHeaders(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.TrustForwardedHeaders = enabled
+	}
+}
+
+// WithObservationRecorder specifies recorder, a histogram registered by the
+// caller, to record the observations attached to requests under name with
+// otelhttp.ContextWithObservation with, labeled like the ServerLatency
+// metric, like the otelhttp.WithObservationRecorder option of the otelhttp
+// Handler. All observations are recorded on the span of the request. It can
+// be used several times, for different names.
+func WithObservationRecorder(name string, recorder metric.Float64ValueRecorder) Option {
+	return func(cfg *config) {
+		if cfg.ObservationRecorders == nil {
+			cfg.ObservationRecorders = make(map[string]metric.Float64ValueRecorder)
+		}
+		cfg.ObservationRecorders[name] = recorder
+	}
+}
+
+// WithRouteParamAttributes specifies whether to record the number of path
+// parameters of the route a request is dispatched to with the
+// RouteParamCountKey attribute, whether the route has a wildcard parameter,
+// like "/static/{subpath:*}", with the RouteWildcardKey attribute, and the
+// number of path segments the wildcard matched with the
+// RouteWildcardSegmentsKey attribute. Deep paths caught by a wildcard route
+// point at routes broader than intended. The attributes are not used as
+// metric labels. It is disabled by default.
+func WithRouteParamAttributes(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.RouteParamAttributes = enabled
+	}
+}
+
+// WithAuthSchemeAttribute specifies whether to record the authentication
+// scheme of the Authorization header of requests, like "Bearer" or "Basic",
+// with the otelhttp.RequestAuthSchemeKey attribute, like the
+// otelhttp.WithAuthSchemeAttribute option of the otelhttp Handler. The
+// credentials of the header are never recorded. It is disabled by default.
+func WithAuthSchemeAttribute(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.AuthSchemeAttribute = enabled
+	}
+}
+
+// WithDebug specifies whether to record attributes diagnosing the
+// instrumentation itself: the index of OTelFilter in the filter chain it
+// runs in, with the FilterIndexKey attribute, and the number of filters of
+// the chain, with the FilterCountKey attribute. go-restful runs the
+// container filters in one chain and those of the WebService and the route
+// in another. Filters running before OTelFilter, like authentication or
+// CORS filters answering requests themselves, are not traced, and the
+// attributes they add to the span of the request are missing, so the
+// position tells whether OTelFilter is installed where intended. It is
+// disabled by default.
+func WithDebug(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.Debug = enabled
+	}
+}
@@ -22,4 +22,8 @@
 //   * the container level
 //   * webservice level
 //   * route level
+//
+// Route functions can enrich the span started for their request, which they
+// get with SpanFromRequest. Code they pass the request context to can add
+// events to it with otelhttp.AddEventToRequestSpan.
 package otelrestful // import "go.opentelemetry.io/contrib/instrumentation/github.com/emicklei/go-restful/otelrestful"
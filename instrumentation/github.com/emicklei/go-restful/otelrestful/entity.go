@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelrestful
+
+import (
+	"mime"
+
+	"github.com/emicklei/go-restful/v3"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// ReadEntity reads the body of req into entity with req.ReadEntity. If the
+// body cannot be parsed, the error is recorded on the span of req as an
+// event with the EntityMediaTypeKey attribute set to the media type of the
+// body, and returned. The span status is still set by OTelFilter from the
+// status of the response once the route function returns.
+//
+//	var user User
+//	if err := otelrestful.ReadEntity(req, &user); err != nil {
+//		resp.WriteError(http.StatusBadRequest, err)
+//		return
+//	}
+func ReadEntity(req *restful.Request, entity interface{}) error {
+	err := req.ReadEntity(entity)
+	if err != nil {
+		SpanFromRequest(req).RecordError(err, oteltrace.WithAttributes(
+			EntityMediaTypeKey.String(entityMediaType(req.HeaderParameter("Content-Type"))),
+		))
+	}
+	return err
+}
+
+// entityMediaType returns the media type of a Content-Type header, without
+// its parameters. go-restful falls back to its default request content type
+// when the header is missing, which cannot be read back, so "unknown" is
+// returned then.
+func entityMediaType(contentType string) string {
+	if contentType == "" {
+		return "unknown"
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
@@ -4,6 +4,7 @@ go 1.14
 
 replace (
 	go.opentelemetry.io/contrib => ../../../../../
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp => ../../../../net/http/otelhttp
 	go.opentelemetry.io/contrib/propagators => ../../../../../propagators
 )
 
@@ -12,6 +13,7 @@ require (
 	github.com/json-iterator/go v1.1.10 // indirect
 	github.com/stretchr/testify v1.6.1
 	go.opentelemetry.io/contrib v0.14.0
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.14.0
 	go.opentelemetry.io/contrib/propagators v0.14.0
 	go.opentelemetry.io/otel v0.14.0
 )
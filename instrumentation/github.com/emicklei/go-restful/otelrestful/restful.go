@@ -15,21 +15,34 @@
 package otelrestful
 
 import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/emicklei/go-restful/v3"
 
 	"go.opentelemetry.io/contrib"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/semconv"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-const tracerName = "go.opentelemetry.io/contrib/instrumentation/github.com/emicklei/go-restful/otelrestful"
+const instrumentationName = "go.opentelemetry.io/contrib/instrumentation/github.com/emicklei/go-restful/otelrestful"
 
 // OTelFilter returns a restful.FilterFunction which will trace an incoming request.
 //
 // The service parameter should describe the name of the (virtual) server handling
 // the request.  Options can be applied to configure the tracer and propagators
-// used for this filter.
+// used for this filter. The duration of each request is recorded with the
+// ServerLatency metric.
+//
+// The route selected for the request is stored in the request context, so
+// that outbound requests made by the handler with an otelhttp Transport
+// configured with otelhttp.WithOriginatingRoute are linked back to it.
 func OTelFilter(service string, opts ...Option) restful.FilterFunction {
 	cfg := config{}
 	for _, opt := range opts {
@@ -39,35 +52,210 @@ func OTelFilter(service string, opts ...Option) restful.FilterFunction {
 		cfg.TracerProvider = otel.GetTracerProvider()
 	}
 	tracer := cfg.TracerProvider.Tracer(
-		tracerName,
+		instrumentationName,
 		oteltrace.WithInstrumentationVersion(contrib.SemVersion()),
 	)
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+	meter := cfg.MeterProvider.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion(contrib.SemVersion()),
+	)
+	latency, err := meter.NewInt64ValueRecorder(
+		ServerLatency,
+		metric.WithDescription("measures the duration of inbound HTTP requests in microseconds"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	var overhead metric.Float64ValueRecorder
+	if cfg.FilterOverhead {
+		overhead, err = meter.NewFloat64ValueRecorder(
+			FilterOverhead,
+			metric.WithDescription("measures the time spent in the go-restful OTelFilter itself, outside of the rest of the filter chain, in microseconds"),
+		)
+		if err != nil {
+			otel.Handle(err)
+		}
+	}
 	if cfg.Propagators == nil {
 		cfg.Propagators = otel.GetTextMapPropagator()
 	}
 	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		requestStartTime := time.Now()
 		r := req.Request
 		ctx := cfg.Propagators.Extract(r.Context(), r.Header)
 		route := req.SelectedRoutePath()
+		if cfg.TrailingSlashNormalization && len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		ws, selected := selectedRoute(cfg.Container, req)
 		spanName := route
 
 		opts := []oteltrace.SpanOption{
-			oteltrace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", r)...),
-			oteltrace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(r)...),
-			oteltrace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, route, r)...),
+			oteltrace.WithAttributes(otelhttp.ServerRequestAttributes(service, route, r)...),
 			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
 		}
+		// The http.method attribute is replaced by the method r is
+		// overridden with if WithMethodOverrideHeader is used.
+		if method, overridden := otelhttp.EffectiveMethod(r, cfg.MethodOverrideHeader); overridden {
+			opts = append(opts, oteltrace.WithAttributes(semconv.HTTPMethodKey.String(method), otelhttp.RequestMethodOriginalKey.String(r.Method)))
+		}
+		scheme, forwarded := "", false
+		if cfg.TrustForwardedHeaders {
+			scheme, forwarded = otelhttp.ForwardedScheme(r)
+		}
+		if forwarded {
+			opts = append(opts, oteltrace.WithAttributes(semconv.HTTPSchemeKey.String(scheme)))
+		}
+		if cfg.AuthSchemeAttribute {
+			if authScheme, ok := otelhttp.AuthScheme(r); ok {
+				opts = append(opts, oteltrace.WithAttributes(otelhttp.RequestAuthSchemeKey.String(authScheme)))
+			}
+		}
+		if cfg.OperationSpanNames && selected != nil {
+			if op := routeOperation(selected); op != "" {
+				spanName = op
+				opts = append(opts, oteltrace.WithAttributes(RouteOperationKey.String(op)))
+			}
+		}
+		if len(cfg.SpanAttributes) > 0 {
+			opts = append(opts, oteltrace.WithAttributes(cfg.SpanAttributes...))
+		}
+		if cfg.ContextAttributeExtractor != nil {
+			if attrs := cfg.ContextAttributeExtractor(r.Context()); len(attrs) > 0 {
+				opts = append(opts, oteltrace.WithAttributes(attrs...))
+			}
+		}
 		ctx, span := tracer.Start(ctx, spanName, opts...)
-		defer span.End()
+		ended := false
+		defer func() {
+			// The span is ended below, unless the filter chain panics.
+			if !ended {
+				span.End()
+			}
+		}()
 
-		// pass the span through the request context
+		span.SetAttributes(mediaTypeAttributes(r, selected)...)
+		if ws != nil {
+			span.SetAttributes(WebServiceKey.String(ws.RootPath()))
+		}
+		if cfg.Debug {
+			index, count := filterPosition(chain)
+			span.SetAttributes(FilterIndexKey.Int(index), FilterCountKey.Int(count))
+		}
+		if cfg.RouteParamAttributes && route != "" {
+			span.SetAttributes(routeParamAttributes(req, req.SelectedRoutePath())...)
+		}
+
+		// pass the span and the route through the request context, the
+		// latter for otelhttp clients configured with WithOriginatingRoute,
+		// and collect the observations attached to the request
+		ctx = otelhttp.ContextWithOriginatingRoute(ctx, route)
+		ctx = otelhttp.ContextWithObservations(ctx)
 		req.Request = req.Request.WithContext(ctx)
 
+		chainStartTime := time.Now()
 		chain.ProcessFilter(req, resp)
+		chainEndTime := time.Now()
+		if cfg.FilterChainDuration {
+			span.SetAttributes(FilterChainDurationKey.Int64(chainEndTime.Sub(chainStartTime).Microseconds()))
+		}
 
 		attrs := semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode())
 		spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode())
 		span.SetAttributes(attrs...)
 		span.SetStatus(spanStatus, spanMessage)
+		if req.SelectedRoutePath() == "" {
+			// go-restful runs the container filters without a selected
+			// route when route selection failed. The same status codes
+			// written by a route function are not negotiation failures.
+			if failure := negotiationFailure(resp.StatusCode()); failure != "" {
+				span.SetAttributes(NegotiationFailureKey.String(failure))
+			}
+		}
+		if ct := resp.Header().Get("Content-Type"); ct != "" {
+			span.SetAttributes(ResponseContentTypeKey.String(ct))
+		}
+		if cfg.RedirectLocation && resp.StatusCode() >= 300 && resp.StatusCode() < 400 {
+			if location := resp.Header().Get("Location"); location != "" {
+				span.SetAttributes(ResponseLocationKey.String(location))
+			}
+		}
+
+		observations := otelhttp.ObservationsFromContext(ctx)
+		span.SetAttributes(observations...)
+
+		labels := append(otelhttp.ServerMetricLabels(service, r, cfg.MethodOverrideHeader, scheme), attrs...)
+		labels = append(labels, semconv.HTTPRouteKey.String(route))
+		if cfg.WebServiceMetricLabel && ws != nil {
+			labels = append(labels, WebServiceKey.String(ws.RootPath()))
+		}
+		if cfg.SortedLabels {
+			sort.SliceStable(labels, func(i, j int) bool { return labels[i].Key < labels[j].Key })
+		}
+		elapsedTime := time.Since(requestStartTime).Microseconds()
+		latency.Record(ctx, elapsedTime, labels...)
+		for _, kv := range observations {
+			if recorder, ok := cfg.ObservationRecorders[string(kv.Key)]; ok {
+				recorder.Record(ctx, kv.Value.AsFloat64(), labels...)
+			}
+		}
+		span.End()
+		ended = true
+		if cfg.FilterOverhead {
+			own := chainStartTime.Sub(requestStartTime) + time.Since(chainEndTime)
+			overhead.Record(ctx, float64(own)/float64(time.Microsecond), labels...)
+		}
+	}
+}
+
+// SpanFromRequest returns the span OTelFilter started for req, so that route
+// functions and the filters following OTelFilter can enrich it without
+// knowing how it is stored in the request context:
+//
+//	otelrestful.SpanFromRequest(req).SetAttributes(label.String("user.id", id))
+//
+// If OTelFilter is not installed for the route of req, or does not run
+// before the caller, a no-op span is returned, on which all calls are
+// safe but have no effect.
+func SpanFromRequest(req *restful.Request) oteltrace.Span {
+	return oteltrace.SpanFromContext(req.Request.Context())
+}
+
+// negotiationFailure returns the NegotiationFailureKey value for a response
+// with the given status code to a request go-restful selected no route for,
+// or an empty string if the status code does not report a content
+// negotiation failure.
+func negotiationFailure(statusCode int) string {
+	switch statusCode {
+	case http.StatusNotAcceptable:
+		return NegotiationFailureAccept
+	case http.StatusUnsupportedMediaType:
+		return NegotiationFailureContentType
+	}
+	return ""
+}
+
+// mediaTypeAttributes returns the attributes describing the content
+// negotiation of r: the media types the request accepts and sends, and those
+// the route selected by go-restful produces and consumes, if known.
+func mediaTypeAttributes(r *http.Request, route *restful.Route) []label.KeyValue {
+	var attrs []label.KeyValue
+	if accept := r.Header.Get("Accept"); accept != "" {
+		attrs = append(attrs, RequestAcceptKey.String(accept))
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		attrs = append(attrs, RequestContentTypeKey.String(ct))
+	}
+	if route != nil {
+		if len(route.Produces) > 0 {
+			attrs = append(attrs, RouteProducesKey.Array(route.Produces))
+		}
+		if len(route.Consumes) > 0 {
+			attrs = append(attrs, RouteConsumesKey.Array(route.Consumes))
+		}
 	}
+	return attrs
 }
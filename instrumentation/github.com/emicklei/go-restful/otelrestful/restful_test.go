@@ -18,16 +18,23 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/contrib/instrumentation/github.com/emicklei/go-restful/otelrestful"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	b3prop "go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/interop"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	otelkv "go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/oteltest"
 	"go.opentelemetry.io/otel/propagation"
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -213,6 +220,58 @@ func TestPropagationWithCustomPropagators(t *testing.T) {
 	container.ServeHTTP(w, r)
 }
 
+func TestPropagationInterop(t *testing.T) {
+	provider := oteltest.NewTracerProvider()
+	propagator := interop.Interop{}
+
+	var outbound http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outbound = r.Header.Clone()
+	}))
+	defer backend.Close()
+	client := http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport,
+		otelhttp.WithTracerProvider(provider),
+		otelhttp.WithPropagators(propagator))}
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	ctx, pspan := provider.Tracer(tracerName).Start(context.Background(), "test")
+	b3prop.B3{InjectEncoding: b3prop.B3SingleHeader}.Inject(ctx, r.Header)
+	require.NotEmpty(t, r.Header.Get("b3"))
+
+	handlerFunc := func(req *restful.Request, resp *restful.Response) {
+		span := oteltrace.SpanFromContext(req.Request.Context())
+		mspan, ok := span.(*oteltest.Span)
+		require.True(t, ok)
+		assert.Equal(t, pspan.SpanContext().TraceID, mspan.SpanContext().TraceID)
+		assert.Equal(t, pspan.SpanContext().SpanID, mspan.ParentSpanID())
+
+		out, err := http.NewRequestWithContext(req.Request.Context(), "GET", backend.URL, nil)
+		require.NoError(t, err)
+		res, err := client.Do(out)
+		require.NoError(t, err)
+		res.Body.Close()
+		resp.WriteHeader(http.StatusOK)
+	}
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(handlerFunc))
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("foobar",
+		otelrestful.WithTracerProvider(provider),
+		otelrestful.WithPropagators(propagator)))
+	container.Add(ws)
+
+	container.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.NotNil(t, outbound)
+	assert.Contains(t, outbound.Get("traceparent"), pspan.SpanContext().TraceID.String())
+	assert.Empty(t, outbound.Get("b3"))
+	assert.Empty(t, outbound.Get("x-b3-traceid"))
+}
+
 func TestMultiFilters(t *testing.T) {
 	sr := new(oteltest.StandardSpanRecorder)
 	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
@@ -259,3 +318,764 @@ func TestMultiFilters(t *testing.T) {
 	w = httptest.NewRecorder()
 	container.ServeHTTP(w, r)
 }
+
+func TestContentNegotiationAttributes(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	handlerFunc := func(mime string) restful.RouteFunction {
+		return func(req *restful.Request, resp *restful.Response) {
+			resp.Header().Set("Content-Type", mime)
+			resp.WriteHeader(http.StatusOK)
+		}
+	}
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").Produces(restful.MIME_JSON).To(handlerFunc(restful.MIME_JSON)))
+	ws.Route(ws.GET("/user/{id}").Produces(restful.MIME_XML).To(handlerFunc(restful.MIME_XML)))
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("foobar",
+		otelrestful.WithTracerProvider(provider),
+		otelrestful.WithContainer(container),
+	))
+	container.Add(ws)
+
+	for _, mime := range []string{restful.MIME_XML, restful.MIME_JSON} {
+		r := httptest.NewRequest("GET", "/user/123", nil)
+		r.Header.Set("Accept", mime)
+		w := httptest.NewRecorder()
+		container.ServeHTTP(w, r)
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	for i, mime := range []string{restful.MIME_XML, restful.MIME_JSON} {
+		attrs := spans[i].Attributes()
+		assert.Equal(t, otelkv.StringValue(mime), attrs[otelrestful.RequestAcceptKey])
+		assert.Equal(t, otelkv.ArrayValue([]string{mime}), attrs[otelrestful.RouteProducesKey])
+		assert.Equal(t, otelkv.StringValue(mime), attrs[otelrestful.ResponseContentTypeKey])
+		assert.NotContains(t, attrs, otelrestful.RouteConsumesKey)
+	}
+}
+
+func TestNegotiationFailure(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ws := &restful.WebService{}
+	ws.Route(ws.POST("/user/{id}").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON).
+		To(func(req *restful.Request, resp *restful.Response) {}))
+	// The route function answers 406 itself, which is not a failure of
+	// the route selection.
+	ws.Route(ws.POST("/refused").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON).
+		To(func(req *restful.Request, resp *restful.Response) {
+			resp.WriteHeader(http.StatusNotAcceptable)
+		}))
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("foobar", otelrestful.WithTracerProvider(provider)))
+	container.Add(ws)
+
+	for _, tc := range []struct {
+		path                string
+		accept, contentType string
+		wantStatus          int
+	}{
+		{path: "/user/123", accept: restful.MIME_XML, contentType: restful.MIME_JSON, wantStatus: http.StatusNotAcceptable},
+		{path: "/user/123", accept: restful.MIME_JSON, contentType: restful.MIME_XML, wantStatus: http.StatusUnsupportedMediaType},
+		{path: "/user/123", accept: restful.MIME_JSON, contentType: restful.MIME_JSON, wantStatus: http.StatusOK},
+		{path: "/refused", accept: restful.MIME_JSON, contentType: restful.MIME_JSON, wantStatus: http.StatusNotAcceptable},
+	} {
+		r := httptest.NewRequest("POST", tc.path, strings.NewReader("{}"))
+		r.Header.Set("Accept", tc.accept)
+		r.Header.Set("Content-Type", tc.contentType)
+		w := httptest.NewRecorder()
+		container.ServeHTTP(w, r)
+		assert.Equal(t, tc.wantStatus, w.Code)
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 4)
+	for i, want := range []string{otelrestful.NegotiationFailureAccept, otelrestful.NegotiationFailureContentType} {
+		assert.Equal(t, otelkv.StringValue(want), spans[i].Attributes()[otelrestful.NegotiationFailureKey])
+		assert.Equal(t, codes.Error, spans[i].StatusCode())
+	}
+	assert.NotContains(t, spans[2].Attributes(), otelrestful.NegotiationFailureKey)
+	assert.Equal(t, codes.Unset, spans[2].StatusCode())
+	assert.NotContains(t, spans[3].Attributes(), otelrestful.NegotiationFailureKey)
+}
+
+func TestOperationSpanNames(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	handlerFunc := func(req *restful.Request, resp *restful.Response) {
+		resp.WriteHeader(http.StatusOK)
+	}
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").Operation("getUser").To(handlerFunc))
+	ws.Route(ws.GET("/group/{id}").To(handlerFunc))
+	ws.Route(ws.GET("/library/{id}").To(libraryHandler))
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("foobar",
+		otelrestful.WithTracerProvider(provider),
+		otelrestful.WithContainer(container),
+		otelrestful.WithOperationSpanNames(true),
+	))
+	container.Add(ws)
+
+	for _, path := range []string{"/user/123", "/group/456", "/library/789"} {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		container.ServeHTTP(w, r)
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "getUser", spans[0].Name())
+	assert.Equal(t, otelkv.StringValue("getUser"), spans[0].Attributes()[otelrestful.RouteOperationKey])
+	assert.Equal(t, otelkv.StringValue("/user/{id}"), spans[0].Attributes()["http.route"])
+	assert.Equal(t, "/group/{id}", spans[1].Name())
+	assert.NotContains(t, spans[1].Attributes(), otelrestful.RouteOperationKey)
+	assert.Equal(t, "/library/{id}", spans[2].Name())
+	assert.NotContains(t, spans[2].Attributes(), otelrestful.RouteOperationKey)
+}
+
+func libraryHandler(req *restful.Request, resp *restful.Response) {
+	resp.WriteHeader(http.StatusOK)
+}
+
+func TestOriginatingRoute(t *testing.T) {
+	var route string
+	var ok bool
+	handlerFunc := func(req *restful.Request, resp *restful.Response) {
+		route, ok = otelhttp.OriginatingRouteFromContext(req.Request.Context())
+		resp.WriteHeader(http.StatusOK)
+	}
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(handlerFunc))
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service", otelrestful.WithTracerProvider(oteltest.NewTracerProvider())))
+	container.Add(ws)
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	container.ServeHTTP(w, r)
+
+	require.True(t, ok)
+	assert.Equal(t, "/user/{id}", route)
+}
+
+func TestServerLatencyMetric(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	handlerFunc := func(req *restful.Request, resp *restful.Response) {
+		resp.WriteHeader(http.StatusOK)
+	}
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(handlerFunc))
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider()),
+		otelrestful.WithMeterProvider(meterProvider),
+	))
+	container.Add(ws)
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	w := httptest.NewRecorder()
+
+	container.ServeHTTP(w, r)
+
+	measured := oteltest.AsStructs(meterimpl.MeasurementBatches)
+	require.Len(t, measured, 1)
+	assert.Equal(t, otelrestful.ServerLatency, measured[0].Name)
+	assert.Equal(t, tracerName, measured[0].InstrumentationName)
+	assert.Equal(t, otelkv.StringValue("/user/{id}"), measured[0].Labels["http.route"])
+	assert.Equal(t, otelkv.IntValue(http.StatusOK), measured[0].Labels["http.status_code"])
+}
+
+func TestFilterChainDuration(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+		handlerFunc := func(req *restful.Request, resp *restful.Response) {
+			time.Sleep(time.Millisecond)
+			resp.WriteHeader(http.StatusOK)
+		}
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/user/{id}").To(handlerFunc))
+
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(provider),
+			otelrestful.WithFilterChainDuration(enabled),
+		))
+		container.Add(ws)
+
+		r := httptest.NewRequest("GET", "/user/123", nil)
+		w := httptest.NewRecorder()
+		container.ServeHTTP(w, r)
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		attrs := spans[0].Attributes()
+		if !enabled {
+			assert.NotContains(t, attrs, otelrestful.FilterChainDurationKey)
+			continue
+		}
+		require.Contains(t, attrs, otelrestful.FilterChainDurationKey)
+		chain := attrs[otelrestful.FilterChainDurationKey].AsInt64()
+		assert.GreaterOrEqual(t, chain, time.Millisecond.Microseconds())
+		end, _ := spans[0].EndTime()
+		assert.LessOrEqual(t, chain, end.Sub(spans[0].StartTime()).Microseconds())
+	}
+}
+
+func TestFilterOverhead(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+		handlerFunc := func(req *restful.Request, resp *restful.Response) {
+			time.Sleep(10 * time.Millisecond)
+			resp.WriteHeader(http.StatusOK)
+		}
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/user/{id}").To(handlerFunc))
+
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(oteltest.NewTracerProvider()),
+			otelrestful.WithMeterProvider(meterProvider),
+			otelrestful.WithFilterOverhead(enabled),
+		))
+		container.Add(ws)
+
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+		measurements := map[string]oteltest.Measured{}
+		for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+			measurements[m.Name] = m
+		}
+		require.Contains(t, measurements, otelrestful.ServerLatency)
+		if !enabled {
+			assert.NotContains(t, measurements, otelrestful.FilterOverhead)
+			continue
+		}
+		require.Contains(t, measurements, otelrestful.FilterOverhead)
+		latency, overhead := measurements[otelrestful.ServerLatency], measurements[otelrestful.FilterOverhead]
+		assert.Equal(t, latency.Labels, overhead.Labels)
+		// The overhead leaves out the route function.
+		assert.Greater(t, overhead.Number.AsFloat64(), 0.0)
+		assert.Less(t, overhead.Number.AsFloat64(), float64(latency.Number.AsInt64()-10*time.Millisecond.Microseconds()+1))
+	}
+}
+
+type logFieldsKey struct{}
+
+func TestContextAttributeExtractor(t *testing.T) {
+	extractor := func(ctx context.Context) []otelkv.KeyValue {
+		fields, _ := ctx.Value(logFieldsKey{}).([]otelkv.KeyValue)
+		return fields
+	}
+	for _, fields := range [][]otelkv.KeyValue{nil, {otelkv.String("request_id", "abc")}} {
+		sr := new(oteltest.StandardSpanRecorder)
+		provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(provider),
+			otelrestful.WithContextAttributeExtractor(extractor),
+		))
+		container.Add(ws)
+
+		r := httptest.NewRequest("GET", "/user/123", nil)
+		if fields != nil {
+			r = r.WithContext(context.WithValue(r.Context(), logFieldsKey{}, fields))
+		}
+		container.ServeHTTP(httptest.NewRecorder(), r)
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		if fields == nil {
+			assert.NotContains(t, spans[0].Attributes(), otelkv.Key("request_id"))
+		} else {
+			assert.Equal(t, otelkv.StringValue("abc"), spans[0].Attributes()["request_id"])
+		}
+	}
+}
+
+func TestResourceAttributesFromEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("OTELRESTFUL_TEST_ATTR_CLOUD_REGION", "eu-west-1"))
+	defer os.Unsetenv("OTELRESTFUL_TEST_ATTR_CLOUD_REGION")
+
+	sr := new(oteltest.StandardSpanRecorder)
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		otelrestful.WithResourceAttributesFromEnv("OTELRESTFUL_TEST_ATTR_"),
+	))
+	container.Add(ws)
+
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, otelkv.StringValue("eu-west-1"), spans[0].Attributes()["cloud.region"])
+}
+
+func TestReadEntityError(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	var readErr error
+	ws := &restful.WebService{}
+	ws.Route(ws.POST("/users").To(func(req *restful.Request, resp *restful.Response) {
+		assert.True(t, otelrestful.SpanFromRequest(req).SpanContext().IsValid())
+		var u user
+		if readErr = otelrestful.ReadEntity(req, &u); readErr != nil {
+			_ = resp.WriteError(http.StatusBadRequest, readErr)
+			return
+		}
+		resp.WriteHeader(http.StatusCreated)
+	}))
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service", otelrestful.WithTracerProvider(provider)))
+	container.Add(ws)
+
+	r := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w := httptest.NewRecorder()
+	container.ServeHTTP(w, r)
+	require.Error(t, readErr)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	events := spans[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, otelkv.StringValue("application/json"), events[0].Attributes[otelrestful.EntityMediaTypeKey])
+}
+
+func TestReadEntity(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	var name string
+	ws := &restful.WebService{}
+	ws.Route(ws.POST("/users").To(func(req *restful.Request, resp *restful.Response) {
+		var u struct {
+			Name string `json:"name"`
+		}
+		require.NoError(t, otelrestful.ReadEntity(req, &u))
+		name = u.Name
+	}))
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service", otelrestful.WithTracerProvider(provider)))
+	container.Add(ws)
+
+	r := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"gopher"}`))
+	r.Header.Set("Content-Type", "application/json")
+	container.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "gopher", name)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Empty(t, spans[0].Events())
+}
+
+func TestSpanFromRequest(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		otelrestful.SpanFromRequest(req).SetAttributes(otelkv.String("user.id", req.PathParameter("id")))
+	}))
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service", otelrestful.WithTracerProvider(provider)))
+	container.Add(ws)
+
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, otelkv.StringValue("123"), spans[0].Attributes()["user.id"])
+}
+
+func TestSpanFromRequestWithoutFilter(t *testing.T) {
+	var span oteltrace.Span
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		span = otelrestful.SpanFromRequest(req)
+		span.SetAttributes(otelkv.String("user.id", req.PathParameter("id")))
+	}))
+	container := restful.NewContainer()
+	container.Add(ws)
+
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	require.NotNil(t, span)
+	assert.False(t, span.SpanContext().IsValid())
+	assert.False(t, span.IsRecording())
+}
+
+func TestWebServiceAttribute(t *testing.T) {
+	for _, metricLabel := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(provider),
+			otelrestful.WithMeterProvider(meterProvider),
+			otelrestful.WithContainer(container),
+			otelrestful.WithWebServiceMetricLabel(metricLabel),
+		))
+		for _, root := range []string{"/api/v1", "/api/v2"} {
+			ws := new(restful.WebService).Path(root)
+			ws.Route(ws.GET("/users/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+			container.Add(ws)
+		}
+
+		for _, path := range []string{"/api/v1/users/1", "/api/v2/users/2", "/api/v1/unknown"} {
+			container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+
+		spans := sr.Completed()
+		require.Len(t, spans, 3)
+		assert.Equal(t, otelkv.StringValue("/api/v1"), spans[0].Attributes()[otelrestful.WebServiceKey])
+		assert.Equal(t, otelkv.StringValue("/api/v2"), spans[1].Attributes()[otelrestful.WebServiceKey])
+		assert.NotContains(t, spans[2].Attributes(), otelrestful.WebServiceKey)
+
+		measured := oteltest.AsStructs(meterimpl.MeasurementBatches)
+		require.Len(t, measured, 3)
+		for i, want := range []string{"/api/v1", "/api/v2", ""} {
+			if !metricLabel || want == "" {
+				assert.NotContains(t, measured[i].Labels, otelrestful.WebServiceKey)
+				continue
+			}
+			assert.Equal(t, otelkv.StringValue(want), measured[i].Labels[otelrestful.WebServiceKey])
+		}
+	}
+}
+
+func TestTrailingSlashNormalization(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/users/").To(func(req *restful.Request, resp *restful.Response) {}))
+		ws.Route(ws.GET("/").To(func(req *restful.Request, resp *restful.Response) {}))
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(provider),
+			otelrestful.WithMeterProvider(meterProvider),
+			otelrestful.WithTrailingSlashNormalization(enabled),
+		))
+		container.Add(ws)
+
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/", nil))
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		want := "/users/"
+		if enabled {
+			want = "/users"
+		}
+		spans := sr.Completed()
+		require.Len(t, spans, 2)
+		assert.Equal(t, want, spans[0].Name())
+		assert.Equal(t, otelkv.StringValue(want), spans[0].Attributes()["http.route"])
+		assert.Equal(t, "/", spans[1].Name())
+
+		measured := oteltest.AsStructs(meterimpl.MeasurementBatches)
+		require.Len(t, measured, 2)
+		assert.Equal(t, otelkv.StringValue(want), measured[0].Labels["http.route"])
+		assert.Equal(t, otelkv.StringValue("/"), measured[1].Labels["http.route"])
+	}
+}
+
+func TestSortedLabels(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider()),
+		otelrestful.WithMeterProvider(meterProvider),
+		otelrestful.WithContainer(container),
+		otelrestful.WithWebServiceMetricLabel(true),
+		otelrestful.WithSortedLabels(true),
+	))
+	ws := new(restful.WebService).Path("/api")
+	ws.Route(ws.GET("/users/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+	container.Add(ws)
+
+	for i := 0; i < 2; i++ {
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/users/1", nil))
+	}
+
+	require.Len(t, meterimpl.MeasurementBatches, 2)
+	labels := meterimpl.MeasurementBatches[0].Labels
+	for i := 1; i < len(labels); i++ {
+		assert.Less(t, string(labels[i-1].Key), string(labels[i].Key))
+	}
+	assert.Equal(t, labels, meterimpl.MeasurementBatches[1].Labels)
+}
+
+func TestMethodOverride(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		otelrestful.WithMeterProvider(meterProvider),
+		otelrestful.WithMethodOverrideHeader("X-HTTP-Method-Override"),
+	))
+	ws := &restful.WebService{}
+	ws.Route(ws.POST("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		resp.WriteHeader(http.StatusNoContent)
+	}))
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+	container.Add(ws)
+
+	r := httptest.NewRequest("POST", "/user/123", nil)
+	r.Header.Set("X-HTTP-Method-Override", "DELETE")
+	w := httptest.NewRecorder()
+	container.ServeHTTP(w, r)
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	// go-restful routes the request by the method it was sent with.
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	assert.Equal(t, otelkv.StringValue("DELETE"), spans[0].Attributes()["http.method"])
+	assert.Equal(t, otelkv.StringValue("POST"), spans[0].Attributes()[otelhttp.RequestMethodOriginalKey])
+	assert.Equal(t, otelkv.StringValue("GET"), spans[1].Attributes()["http.method"])
+	assert.NotContains(t, spans[1].Attributes(), otelhttp.RequestMethodOriginalKey)
+
+	var methods []string
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		methods = append(methods, m.Labels["http.method"].AsString())
+	}
+	assert.Equal(t, []string{"DELETE", "GET"}, methods)
+}
+
+func TestRedirectLocation(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			otelrestful.WithRedirectLocation(enabled),
+		))
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/old/{id}").To(func(req *restful.Request, resp *restful.Response) {
+			http.Redirect(resp, req.Request, "/new/"+req.PathParameter("id"), http.StatusFound)
+		}))
+		ws.Route(ws.GET("/created").To(func(req *restful.Request, resp *restful.Response) {
+			resp.Header().Set("Location", "/new/1")
+			resp.WriteHeader(http.StatusCreated)
+		}))
+		container.Add(ws)
+
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/old/1", nil))
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/created", nil))
+
+		spans := sr.Completed()
+		require.Len(t, spans, 2)
+		if enabled {
+			assert.Equal(t, otelkv.StringValue("/new/1"), spans[0].Attributes()[otelrestful.ResponseLocationKey])
+		} else {
+			assert.NotContains(t, spans[0].Attributes(), otelrestful.ResponseLocationKey)
+		}
+		// Only redirects are recorded.
+		assert.NotContains(t, spans[1].Attributes(), otelrestful.ResponseLocationKey)
+	}
+}
+
+func TestTrustForwardedHeaders(t *testing.T) {
+	for _, trust := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+		container := restful.NewContainer()
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			otelrestful.WithMeterProvider(meterProvider),
+			otelrestful.WithTrustForwardedHeaders(trust),
+		))
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+		container.Add(ws)
+
+		r := httptest.NewRequest("GET", "/user/123", nil)
+		r.Header.Set("Forwarded", "for=192.0.2.60;proto=https")
+		container.ServeHTTP(httptest.NewRecorder(), r)
+
+		want := otelkv.StringValue("http")
+		if trust {
+			want = otelkv.StringValue("https")
+		}
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		assert.Equal(t, want, spans[0].Attributes()["http.scheme"])
+		measurements := oteltest.AsStructs(meterimpl.MeasurementBatches)
+		require.Len(t, measurements, 1)
+		assert.Equal(t, want, measurements[0].Labels["http.scheme"])
+	}
+}
+
+func TestObservations(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	rows := metric.Must(meterProvider.Meter("test")).NewFloat64ValueRecorder("rows")
+
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		otelrestful.WithMeterProvider(meterProvider),
+		otelrestful.WithObservationRecorder("rows", rows),
+	))
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		ctx := otelhttp.ContextWithObservation(req.Request.Context(), "rows", 5)
+		otelhttp.ContextWithObservation(ctx, "items", 2)
+	}))
+	container.Add(ws)
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, otelkv.Float64Value(5), spans[0].Attributes()["rows"])
+	assert.Equal(t, otelkv.Float64Value(2), spans[0].Attributes()["items"])
+	var recorded []oteltest.Measured
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == "rows" {
+			recorded = append(recorded, m)
+		}
+	}
+	require.Len(t, recorded, 1)
+	assert.Equal(t, float64(5), recorded[0].Number.AsFloat64())
+	assert.Equal(t, otelkv.StringValue("/user/{id}"), recorded[0].Labels["http.route"])
+}
+
+func TestRouteParamAttributes(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		otelrestful.WithRouteParamAttributes(true),
+	))
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+	ws.Route(ws.GET("/static/{subpath:*}").To(func(req *restful.Request, resp *restful.Response) {}))
+	container.Add(ws)
+
+	deep := "/static" + strings.Repeat("/a", 40)
+	for _, path := range []string{"/user/123", "/static/css/site.css", deep} {
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Equal(t, otelkv.IntValue(1), spans[0].Attributes()[otelrestful.RouteParamCountKey])
+	assert.Equal(t, otelkv.BoolValue(false), spans[0].Attributes()[otelrestful.RouteWildcardKey])
+	assert.NotContains(t, spans[0].Attributes(), otelrestful.RouteWildcardSegmentsKey)
+	assert.Equal(t, otelkv.IntValue(1), spans[1].Attributes()[otelrestful.RouteParamCountKey])
+	assert.Equal(t, otelkv.BoolValue(true), spans[1].Attributes()[otelrestful.RouteWildcardKey])
+	assert.Equal(t, otelkv.IntValue(2), spans[1].Attributes()[otelrestful.RouteWildcardSegmentsKey])
+	assert.Equal(t, otelkv.IntValue(32), spans[2].Attributes()[otelrestful.RouteWildcardSegmentsKey])
+}
+
+func TestAuthSchemeAttribute(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service",
+		otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		otelrestful.WithAuthSchemeAttribute(true),
+	))
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+	container.Add(ws)
+
+	for _, auth := range []string{"Basic dXNlcjpwYXNz", ""} {
+		r := httptest.NewRequest("GET", "/user/123", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		container.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	assert.Equal(t, otelkv.StringValue("Basic"), spans[0].Attributes()[otelhttp.RequestAuthSchemeKey])
+	assert.NotContains(t, spans[1].Attributes(), otelhttp.RequestAuthSchemeKey)
+}
+
+func TestDebugFilterPosition(t *testing.T) {
+	pass := func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		chain.ProcessFilter(req, resp)
+	}
+	for _, debug := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		container := restful.NewContainer()
+		container.Filter(pass)
+		container.Filter(otelrestful.OTelFilter("my-service",
+			otelrestful.WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			otelrestful.WithDebug(debug),
+		))
+		container.Filter(pass)
+		ws := &restful.WebService{}
+		ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {}))
+		container.Add(ws)
+		container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		if !debug {
+			assert.NotContains(t, spans[0].Attributes(), otelrestful.FilterIndexKey)
+			assert.NotContains(t, spans[0].Attributes(), otelrestful.FilterCountKey)
+			continue
+		}
+		assert.Equal(t, otelkv.IntValue(1), spans[0].Attributes()[otelrestful.FilterIndexKey])
+		assert.Equal(t, otelkv.IntValue(3), spans[0].Attributes()[otelrestful.FilterCountKey])
+	}
+}
+
+func TestAddEventToRequestSpan(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	container := restful.NewContainer()
+	container.Filter(otelrestful.OTelFilter("my-service", otelrestful.WithTracerProvider(provider)))
+	ws := &restful.WebService{}
+	ws.Route(ws.GET("/user/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		otelhttp.AddEventToRequestSpan(req.Request.Context(), "user.loaded", otelkv.String("user.id", req.PathParameter("id")))
+	}))
+	container.Add(ws)
+	container.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	events := spans[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "user.loaded", events[0].Name)
+	assert.Equal(t, otelkv.StringValue("123"), events[0].Attributes["user.id"])
+}
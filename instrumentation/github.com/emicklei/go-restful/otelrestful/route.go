@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelrestful
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// selectedRoute returns the WebService and Route of c that go-restful
+// dispatched req to. The lookup uses the selected route path and the request
+// method, and, when several routes share them, the same content negotiation
+// go-restful applies. Nil values are returned if c is nil or no registered
+// route matches.
+func selectedRoute(c *restful.Container, req *restful.Request) (*restful.WebService, *restful.Route) {
+	if c == nil {
+		return nil, nil
+	}
+	path := req.SelectedRoutePath()
+	if path == "" {
+		return nil, nil
+	}
+
+	var (
+		candidateWS    *restful.WebService
+		candidateRoute *restful.Route
+	)
+	for _, ws := range c.RegisteredWebServices() {
+		for _, route := range ws.Routes() {
+			if route.Method != req.Request.Method || route.Path != path {
+				continue
+			}
+			route := route
+			if candidateRoute == nil {
+				candidateWS, candidateRoute = ws, &route
+			}
+			if matchesMediaTypes(req.Request.Header.Get("Content-Type"), route.Consumes) &&
+				matchesMediaTypes(req.Request.Header.Get("Accept"), route.Produces) {
+				return ws, &route
+			}
+		}
+	}
+	return candidateWS, candidateRoute
+}
+
+// matchesMediaTypes returns whether any of the comma separated media types in
+// header matches one of supported. An empty header or supported list, as well
+// as the */* wildcard, match anything.
+func matchesMediaTypes(header string, supported []string) bool {
+	if header == "" || len(supported) == 0 {
+		return true
+	}
+	for _, mimeType := range strings.Split(header, ",") {
+		if i := strings.Index(mimeType, ";"); i != -1 {
+			mimeType = mimeType[:i]
+		}
+		mimeType = strings.TrimSpace(mimeType)
+		if mimeType == "*/*" {
+			return true
+		}
+		for _, s := range supported {
+			if s == "*/*" || s == mimeType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anonymousOperation matches the operation names go-restful gives the routes
+// of anonymous functions.
+var anonymousOperation = regexp.MustCompile(`^func[0-9]+$`)
+
+// routeOperation returns the operation name set for route with
+// restful.RouteBuilder.Operation, or "" if there is none. go-restful names
+// the operation of routes built without one after their function, which is
+// neither stable nor meaningful for closures, so such names are ignored.
+func routeOperation(route *restful.Route) string {
+	op := route.Operation
+	if op == "" || anonymousOperation.MatchString(op) {
+		return ""
+	}
+	if route.Function != nil {
+		if fn := runtime.FuncForPC(reflect.ValueOf(route.Function).Pointer()); fn != nil {
+			name := fn.Name()
+			name = name[strings.LastIndex(name, ".")+1:]
+			if op == strings.TrimSuffix(name, "-fm") {
+				return ""
+			}
+		}
+	}
+	return op
+}
+
+// maxWildcardSegments bounds the RouteWildcardSegmentsKey attribute.
+const maxWildcardSegments = 32
+
+// routeParamAttributes returns the attributes describing the path parameters
+// of the route req was dispatched to, whose path template is route.
+func routeParamAttributes(req *restful.Request, route string) []label.KeyValue {
+	attrs := []label.KeyValue{RouteParamCountKey.Int(len(req.PathParameters()))}
+	name, ok := wildcardParam(route)
+	attrs = append(attrs, RouteWildcardKey.Bool(ok))
+	if ok {
+		segments := 0
+		for _, s := range strings.Split(req.PathParameter(name), "/") {
+			if s != "" {
+				segments++
+			}
+		}
+		if segments > maxWildcardSegments {
+			segments = maxWildcardSegments
+		}
+		attrs = append(attrs, RouteWildcardSegmentsKey.Int(segments))
+	}
+	return attrs
+}
+
+// wildcardParam returns the name of the wildcard path parameter of the route
+// path template route, like "subpath" for "/static/{subpath:*}", and whether
+// it has one.
+func wildcardParam(route string) (string, bool) {
+	for _, segment := range strings.Split(route, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, ":*}") {
+			return strings.TrimSpace(segment[1 : len(segment)-len(":*}")]), true
+		}
+	}
+	return "", false
+}
+
+// filterPosition returns the index of the filter being processed by chain,
+// counted from 0, and the number of filters of chain. go-restful runs the
+// container filters in one chain, and the filters of the WebService and of
+// the route in another one.
+func filterPosition(chain *restful.FilterChain) (index, count int) {
+	return chain.Index - 1, len(chain.Filters)
+}
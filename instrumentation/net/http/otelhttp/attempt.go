@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attempts counts the requests sent for a logical request, see
+// WithPerAttemptSpans.
+type attempts struct {
+	n int64
+}
+
+// next returns the number of requests sent for the logical request before
+// the current one.
+func (a *attempts) next() int64 {
+	return atomic.AddInt64(&a.n, 1) - 1
+}
+
+// summarize sets the number of resends of the logical request as the
+// ResendCountKey attribute of its span, if it was resent. It is a no-op if a
+// is nil.
+func (a *attempts) summarize(span trace.Span) {
+	if a == nil {
+		return
+	}
+	if n := atomic.LoadInt64(&a.n); n > 1 {
+		span.SetAttributes(ResendCountKey.Int64(n - 1))
+	}
+}
+
+type attemptsContextKeyType int
+
+const attemptsContextKey attemptsContextKeyType = 0
+
+func contextWithAttempts(parent context.Context) (context.Context, *attempts) {
+	a := &attempts{}
+	return context.WithValue(parent, attemptsContextKey, a), a
+}
+
+func attemptsFromContext(ctx context.Context) *attempts {
+	a, _ := ctx.Value(attemptsContextKey).(*attempts)
+	return a
+}
+
+// logicalSpan is the span of a logical request started with StartRequest,
+// which records the number of resends of the request when it ends.
+type logicalSpan struct {
+	trace.Span
+	attempts *attempts
+}
+
+func (s logicalSpan) End(options ...trace.SpanOption) {
+	s.attempts.summarize(s.Span)
+	s.Span.End(options...)
+}
+
+// StartRequest starts the span, named name, of a logical request, like the
+// call of a resilient client that retries requests until one succeeds, and
+// returns a context carrying it. The requests sent with the returned
+// context through a Transport of this package are traced as attempts of the
+// logical request: their spans are children of its span, and have the
+// ResendCountKey attribute for attempts after the first. The caller must
+// end the returned span once the logical request completes, which then has
+// the total number of resends as its ResendCountKey attribute, if any.
+// Unlike with WithPerAttemptSpans, the Transport records the metrics of
+// each attempt. Of opts, only WithTracerProvider and WithSpanOptions are
+// used.
+//
+//	ctx, span := otelhttp.StartRequest(ctx, "GetUser")
+//	defer span.End()
+//	for attempt := 0; attempt < 3; attempt++ {
+//		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//		if res, err := client.Do(req); err == nil {
+//			...
+//		}
+//	}
+func StartRequest(ctx context.Context, name string, opts ...Option) (context.Context, trace.Span) {
+	c := newConfig(opts...)
+	ctx, span := c.Tracer.Start(ctx, name, c.SpanStartOptions...)
+	ctx, a := contextWithAttempts(ctx)
+	return ctx, logicalSpan{Span: span, attempts: a}
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+// retrier resends requests answered with 503 Service Unavailable once.
+type retrier struct {
+	rt http.RoundTripper
+}
+
+func (r retrier) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.rt.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusServiceUnavailable {
+		return res, err
+	}
+	res.Body.Close()
+	return r.rt.RoundTrip(req)
+}
+
+func TestPerAttemptSpans(t *testing.T) {
+	var calls int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	opts := []Option{WithTracerProvider(provider), WithMeterProvider(meterProvider)}
+	attempt := NewTransport(http.DefaultTransport, opts...)
+	tr := NewTransport(retrier{attempt}, append(opts, WithPerAttemptSpans(true))...)
+
+	res, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	// The logical request ends, closing the body of the second attempt,
+	// before the second attempt.
+	first, logical, second := spans[0], spans[1], spans[2]
+
+	assert.False(t, logical.ParentSpanID().IsValid())
+	assert.Equal(t, logical.SpanContext().SpanID, first.ParentSpanID())
+	assert.Equal(t, logical.SpanContext().SpanID, second.ParentSpanID())
+	assert.NotContains(t, first.Attributes(), ResendCountKey)
+	assert.Equal(t, codes.Error, first.StatusCode())
+	assert.Equal(t, label.Int64Value(1), second.Attributes()[ResendCountKey])
+	assert.Equal(t, codes.Unset, second.StatusCode())
+
+	assert.Equal(t, label.Int64Value(1), logical.Attributes()[ResendCountKey])
+	assert.Equal(t, codes.Unset, logical.StatusCode())
+
+	var durations int
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestDuration {
+			durations++
+		}
+	}
+	assert.Equal(t, 1, durations, "only the logical request is measured")
+}
+
+func TestPerAttemptSpansWithoutResend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	attempt := NewTransport(http.DefaultTransport, WithTracerProvider(provider))
+	tr := NewTransport(retrier{attempt}, WithTracerProvider(provider), WithPerAttemptSpans(true))
+
+	res, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	for _, s := range spans {
+		assert.NotContains(t, s.Attributes(), ResendCountKey)
+	}
+}
+
+func TestStartRequest(t *testing.T) {
+	var calls int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, WithTracerProvider(provider))}
+
+	ctx, span := StartRequest(context.Background(), "GetUser", WithTracerProvider(provider))
+	for attempt := 0; attempt < 5; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		if res.StatusCode == http.StatusOK {
+			break
+		}
+	}
+	span.End()
+
+	spans := sr.Completed()
+	require.Len(t, spans, 4)
+	logical := spans[3]
+	assert.Equal(t, "GetUser", logical.Name())
+	assert.Equal(t, label.Int64Value(2), logical.Attributes()[ResendCountKey])
+	for i, s := range spans[:3] {
+		assert.Equal(t, logical.SpanContext().SpanID, s.ParentSpanID())
+		if i == 0 {
+			assert.NotContains(t, s.Attributes(), ResendCountKey)
+		} else {
+			assert.Equal(t, label.Int64Value(int64(i)), s.Attributes()[ResendCountKey])
+		}
+	}
+}
+
+func TestStartRequestWithoutResend(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	_, span := StartRequest(context.Background(), "GetUser", WithTracerProvider(provider))
+	span.End()
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), ResendCountKey)
+}
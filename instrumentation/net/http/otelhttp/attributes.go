@@ -0,0 +1,196 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// ClientRequestAttributes returns the attributes the Transport records for
+// an outbound request r, on its span and as the labels of its metrics. It is
+// meant for custom instrumentation that needs to stay consistent with the
+// Transport.
+func ClientRequestAttributes(r *http.Request) []label.KeyValue {
+	return semconv.HTTPClientAttributesFromHTTPRequest(r)
+}
+
+// ServerRequestAttributes returns the attributes the Handler records on the
+// span of an inbound request r, served by the named service, for the given
+// route. route may be empty if it is not known when the span is started. It
+// is meant for custom instrumentation, like that of routers, that needs to
+// stay consistent with the Handler.
+func ServerRequestAttributes(service, route string, r *http.Request) []label.KeyValue {
+	attrs := semconv.NetAttributesFromHTTPRequest("tcp", r)
+	attrs = append(attrs, semconv.EndUserAttributesFromHTTPRequest(r)...)
+	return append(attrs, semconv.HTTPServerAttributesFromHTTPRequest(service, route, r)...)
+}
+
+// EffectiveMethod returns the method a POST request r is overridden with by
+// its header named header, like X-HTTP-Method-Override, which APIs use to
+// tunnel methods like PUT and DELETE through clients and proxies only
+// allowing GET and POST, and whether r is overridden. The value of the
+// header is case insensitive. Requests with another method, without the
+// header or with a value that is not one of the methods defined by
+// net/http, like http.MethodDelete, are not overridden.
+func EffectiveMethod(r *http.Request, header string) (string, bool) {
+	if r.Method != http.MethodPost || header == "" {
+		return r.Method, false
+	}
+	method := strings.ToUpper(strings.TrimSpace(r.Header.Get(header)))
+	if method == r.Method || !knownMethod(method) {
+		return r.Method, false
+	}
+	return method, true
+}
+
+// knownMethod returns whether method is one of the methods defined by
+// net/http.
+func knownMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// MethodLabel returns the http.method label the Handler records on its
+// metrics for method when WithMethodOverrideHeader is used, with methods not
+// defined by net/http replaced by "_OTHER" to keep the label cardinality
+// bounded. Like ServerRequestAttributes, it is meant for custom
+// instrumentation that needs to stay consistent with the Handler.
+func MethodLabel(method string) label.KeyValue {
+	if !knownMethod(method) {
+		method = "_OTHER"
+	}
+	return semconv.HTTPMethodKey.String(method)
+}
+
+// ServerMetricLabels returns the labels the Handler records on the metrics
+// of an inbound request r, served by the named service, apart from those of
+// its Labeler and its status code. The method of r is the one returned by
+// EffectiveMethod for header, which may be empty, see
+// WithMethodOverrideHeader, and the http.scheme label is scheme, if not
+// empty, like a scheme returned by ForwardedScheme, see
+// WithTrustForwardedHeaders. Like ServerRequestAttributes, it is meant for
+// custom instrumentation that needs to stay consistent with the Handler.
+func ServerMetricLabels(service string, r *http.Request, header, scheme string) []label.KeyValue {
+	method, overridden := EffectiveMethod(r, header)
+	if overridden {
+		r = withMethod(r, method)
+	}
+	labels := semconv.HTTPServerMetricAttributesFromHTTPRequest(service, r)
+	if header != "" {
+		labels = append(labels, MethodLabel(method))
+	}
+	if scheme != "" {
+		labels = setLabel(labels, semconv.HTTPSchemeKey.String(scheme))
+	}
+	return labels
+}
+
+// withMethod returns a shallow copy of r with its method replaced by method,
+// for the attributes of r to record it.
+func withMethod(r *http.Request, method string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.Method = method
+	return r2
+}
+
+// ForwardedScheme returns the scheme, "http" or "https", the client of r
+// used according to the proxies that forwarded it, and whether they
+// reported one. It is read from the proto parameter of the first element of
+// the Forwarded header, see https://tools.ietf.org/html/rfc7239, or, without
+// it, from the first value of the X-Forwarded-Proto header. Clients can set
+// these headers themselves, so they are only to be trusted behind proxies
+// overwriting them, see WithTrustForwardedHeaders.
+func ForwardedScheme(r *http.Request) (string, bool) {
+	var scheme string
+	if forwarded := r.Header.Get("Forwarded"); forwarded != "" {
+		first := strings.SplitN(forwarded, ",", 2)[0]
+		for _, pair := range strings.Split(first, ";") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) == 2 && strings.EqualFold(kv[0], "proto") {
+				scheme = strings.Trim(kv[1], `"`)
+				break
+			}
+		}
+	} else if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.SplitN(proto, ",", 2)[0]
+	}
+	switch scheme = strings.ToLower(strings.TrimSpace(scheme)); scheme {
+	case "http", "https":
+		return scheme, true
+	}
+	return "", false
+}
+
+// APIVersionFromAccept returns the version of the API a request r asks for
+// with the version parameter of the first media type of its Accept header,
+// like "2" for "application/vnd.api+json;version=2", or "" if it has none.
+// It is meant to be used with WithAPIVersionExtractor.
+func APIVersionFromAccept(r *http.Request) string {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(strings.SplitN(accept, ",", 2)[0])
+	if err != nil {
+		return ""
+	}
+	return params["version"]
+}
+
+// APIVersionFromHeader returns a function returning the version of the API
+// a request asks for with its header named header, like "Api-Version", or ""
+// if it has none. It is meant to be used with WithAPIVersionExtractor.
+func APIVersionFromHeader(header string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.Header.Get(header))
+	}
+}
+
+// authSchemes are the authentication schemes AuthScheme reports, by their
+// lower case name.
+var authSchemes = func() map[string]string {
+	schemes := map[string]string{}
+	for _, s := range []string{"Basic", "Bearer", "Digest", "DPoP", "HOBA", "Mutual", "Negotiate", "NTLM", "AWS4-HMAC-SHA256"} {
+		schemes[strings.ToLower(s)] = s
+	}
+	return schemes
+}()
+
+// AuthScheme returns the authentication scheme of the Authorization header
+// of r, like "Bearer" or "Basic", and whether r has one. The credentials
+// are never returned: as a header holding only a credential cannot be told
+// apart from a scheme, schemes other than the common ones registered with
+// IANA, like Basic, Bearer, Digest, Negotiate and NTLM, are returned as
+// "_OTHER".
+func AuthScheme(r *http.Request) (string, bool) {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) == 0 {
+		return "", false
+	}
+	if s, ok := authSchemes[strings.ToLower(fields[0])]; ok {
+		return s, true
+	}
+	return "_OTHER", true
+}
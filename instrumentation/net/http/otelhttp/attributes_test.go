@@ -0,0 +1,290 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestServerRequestAttributes(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+	)
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	r.SetBasicAuth("gopher", "secret")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	attrs := ServerRequestAttributes("test_handler", "", r)
+	require.NotEmpty(t, attrs)
+	for _, kv := range attrs {
+		assert.Equal(t, kv.Value, spans[0].Attributes()[kv.Key], kv.Key)
+	}
+
+	withRoute := ServerRequestAttributes("test_handler", "/users/{id}", r)
+	assert.Contains(t, withRoute, semconv.HTTPRouteKey.String("/users/{id}"))
+}
+
+func TestClientRequestAttributes(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+	)
+
+	r, err := http.NewRequest(http.MethodPost, "http://example.com/users", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	attrs := ClientRequestAttributes(r)
+	require.NotEmpty(t, attrs)
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	for _, kv := range attrs {
+		assert.Equal(t, kv.Value, spans[0].Attributes()[kv.Key], kv.Key)
+	}
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestDuration {
+			for _, kv := range attrs {
+				assert.Equal(t, kv.Value, m.Labels[kv.Key], kv.Key)
+			}
+		}
+	}
+}
+
+func TestEffectiveMethod(t *testing.T) {
+	for _, tc := range []struct {
+		name, method, override, want string
+		overridden                   bool
+	}{
+		{name: "not set", method: http.MethodPost, want: http.MethodPost},
+		{name: "post", method: http.MethodPost, override: "delete", want: http.MethodDelete, overridden: true},
+		{name: "get", method: http.MethodGet, override: "DELETE", want: http.MethodGet},
+		{name: "unknown", method: http.MethodPost, override: "PURGE", want: http.MethodPost},
+		{name: "same", method: http.MethodPost, override: "POST", want: http.MethodPost},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/users/42", nil)
+			if tc.override != "" {
+				r.Header.Set("X-HTTP-Method-Override", tc.override)
+			}
+			method, overridden := EffectiveMethod(r, "X-HTTP-Method-Override")
+			assert.Equal(t, tc.want, method)
+			assert.Equal(t, tc.overridden, overridden)
+		})
+	}
+}
+
+func TestServerMetricLabels(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/users/42", nil)
+	r.Header.Set("X-HTTP-Method-Override", "DELETE")
+
+	labels := ServerMetricLabels("test_handler", r, "", "")
+	assert.Equal(t, semconv.HTTPServerMetricAttributesFromHTTPRequest("test_handler", r), labels)
+
+	labels = ServerMetricLabels("test_handler", r, "X-HTTP-Method-Override", "https")
+	got := map[label.Key]label.Value{}
+	for _, kv := range labels {
+		got[kv.Key] = kv.Value
+	}
+	assert.Equal(t, label.StringValue(http.MethodDelete), got[semconv.HTTPMethodKey])
+	assert.Equal(t, label.StringValue("https"), got[semconv.HTTPSchemeKey])
+	// r itself is not modified.
+	assert.Equal(t, http.MethodPost, r.Method)
+}
+
+func TestHandlerMethodOverride(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	var served string
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { served = r.Method }), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithMethodOverrideHeader("X-HTTP-Method-Override"),
+		WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.Method }),
+	)
+	r := httptest.NewRequest(http.MethodPost, "/users/42", nil)
+	r.Header.Set("X-HTTP-Method-Override", "DELETE")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, http.MethodPost, served)
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, http.MethodDelete, spans[0].Name())
+	assert.Equal(t, label.StringValue(http.MethodDelete), spans[0].Attributes()[semconv.HTTPMethodKey])
+	assert.Equal(t, label.StringValue(http.MethodPost), spans[0].Attributes()[RequestMethodOriginalKey])
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		assert.Equal(t, label.StringValue(http.MethodDelete), m.Labels[semconv.HTTPMethodKey], m.Name)
+	}
+}
+
+func TestForwardedScheme(t *testing.T) {
+	for _, tc := range []struct {
+		headers map[string]string
+		scheme  string
+		ok      bool
+	}{
+		{headers: nil},
+		{headers: map[string]string{"X-Forwarded-Proto": "https"}, scheme: "https", ok: true},
+		{headers: map[string]string{"X-Forwarded-Proto": "HTTPS, http"}, scheme: "https", ok: true},
+		{headers: map[string]string{"X-Forwarded-Proto": "ftp"}},
+		{headers: map[string]string{"Forwarded": `for=192.0.2.60;Proto="https";by=203.0.113.43, proto=http`}, scheme: "https", ok: true},
+		{headers: map[string]string{"Forwarded": "proto=http", "X-Forwarded-Proto": "https"}, scheme: "http", ok: true},
+		{headers: map[string]string{"Forwarded": "for=192.0.2.60"}},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range tc.headers {
+			r.Header.Set(k, v)
+		}
+		scheme, ok := ForwardedScheme(r)
+		assert.Equal(t, tc.scheme, scheme, tc.headers)
+		assert.Equal(t, tc.ok, ok, tc.headers)
+	}
+}
+
+func TestHandlerTrustForwardedHeaders(t *testing.T) {
+	for _, trust := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+		h := NewHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithMeterProvider(meterProvider),
+			WithTrustForwardedHeaders(trust),
+		)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-Proto", "https")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		want := semconv.HTTPSchemeHTTP.Value
+		if trust {
+			want = semconv.HTTPSchemeHTTPS.Value
+		}
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		assert.Equal(t, want, spans[0].Attributes()[semconv.HTTPSchemeKey])
+		for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+			assert.Equal(t, want, m.Labels[semconv.HTTPSchemeKey], m.Name)
+		}
+	}
+}
+
+func TestAPIVersionExtractors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, "", APIVersionFromAccept(r))
+	assert.Equal(t, "", APIVersionFromHeader("Api-Version")(r))
+
+	r.Header.Set("Accept", "application/vnd.api+json; version=2, application/json")
+	r.Header.Set("Api-Version", " 2020-01-01 ")
+	assert.Equal(t, "2", APIVersionFromAccept(r))
+	assert.Equal(t, "2020-01-01", APIVersionFromHeader("Api-Version")(r))
+
+	r.Header.Set("Accept", "application/json, application/vnd.api+json;version=2")
+	assert.Equal(t, "", APIVersionFromAccept(r))
+}
+
+func TestHandlerAPIVersion(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	h := NewHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithAPIVersionExtractor(APIVersionFromAccept),
+		WithAPIVersionLabels("1", "2"),
+	)
+	for _, accept := range []string{"application/vnd.api+json;version=2", "application/vnd.api+json;version=42", "application/json"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Equal(t, label.StringValue("2"), spans[0].Attributes()[APIVersionKey])
+	assert.Equal(t, label.StringValue("42"), spans[1].Attributes()[APIVersionKey])
+	assert.NotContains(t, spans[2].Attributes(), APIVersionKey)
+
+	var versions []string
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == ServerLatency {
+			versions = append(versions, m.Labels[APIVersionKey].AsString())
+		}
+	}
+	assert.Equal(t, []string{"2", "_OTHER", ""}, versions)
+}
+
+func TestAuthScheme(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		scheme string
+		ok     bool
+	}{
+		{header: ""},
+		{header: "   "},
+		{header: "Bearer eyJhbGciOiJIUzI1NiJ9.e30.sig", scheme: "Bearer", ok: true},
+		{header: "basic dXNlcjpwYXNz", scheme: "Basic", ok: true},
+		{header: "Negotiate\tYIIJvwYGKwYBBQUCoIIJszCCCa+gJDAi", scheme: "Negotiate", ok: true},
+		// A credential without a scheme is never recorded.
+		{header: "sk-live-0123456789abcdef", scheme: "_OTHER", ok: true},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.header != "" {
+			r.Header.Set("Authorization", tc.header)
+		}
+		scheme, ok := AuthScheme(r)
+		assert.Equal(t, tc.scheme, scheme, tc.header)
+		assert.Equal(t, tc.ok, ok, tc.header)
+	}
+}
+
+func TestHandlerAuthSchemeAttribute(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		h := NewHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithAuthSchemeAttribute(enabled),
+		)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer secret-token")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		if enabled {
+			assert.Equal(t, label.StringValue("Bearer"), spans[0].Attributes()[RequestAuthSchemeKey])
+		} else {
+			assert.NotContains(t, spans[0].Attributes(), RequestAuthSchemeKey)
+		}
+		for k, v := range spans[0].Attributes() {
+			assert.NotContains(t, v.Emit(), "secret-token", k)
+		}
+	}
+}
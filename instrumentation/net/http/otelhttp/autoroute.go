@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// routeIDPlaceholder replaces the segments of URL paths that look like IDs,
+// see WithAutoRouteNormalization.
+const routeIDPlaceholder = "{id}"
+
+// DefaultRouteIDPatterns returns the patterns WithAutoRouteNormalization
+// uses if none are given. They match path segments that are decimal
+// numbers, like "42", UUIDs, like "123e4567-e89b-12d3-a456-426614174000",
+// and hexadecimal strings of 16 characters or more, like MongoDB ObjectIDs
+// or hashes. A new slice is returned on each call, so it can be extended.
+func DefaultRouteIDPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		regexp.MustCompile(`^[0-9]+$`),
+		regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+		regexp.MustCompile(`^[0-9a-fA-F]{16,}$`),
+	}
+}
+
+// replaceRouteIDs returns path with the segments matched by any of patterns
+// replaced with "{id}".
+func replaceRouteIDs(path string, patterns []*regexp.Regexp) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		for _, p := range patterns {
+			if p.MatchString(segment) {
+				segments[i] = routeIDPlaceholder
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestReplaceRouteIDs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "root", path: "/", want: "/"},
+		{name: "no ID", path: "/users/me", want: "/users/me"},
+		{name: "numeric", path: "/users/42", want: "/users/{id}"},
+		{name: "UUID", path: "/orders/123e4567-e89b-12d3-a456-426614174000", want: "/orders/{id}"},
+		{name: "upper case UUID", path: "/orders/123E4567-E89B-12D3-A456-426614174000", want: "/orders/{id}"},
+		{name: "hex", path: "/objects/5f8d0d55b54764421b7156c3", want: "/objects/{id}"},
+		{name: "short hex", path: "/colors/beef", want: "/colors/beef"},
+		{name: "mixed", path: "/users/42/orders/123e4567-e89b-12d3-a456-426614174000/items", want: "/users/{id}/orders/{id}/items"},
+		{name: "trailing slash", path: "/users/42/", want: "/users/{id}/"},
+		{name: "version", path: "/v2/users/42", want: "/v2/users/{id}"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, replaceRouteIDs(tc.path, DefaultRouteIDPatterns()))
+		})
+	}
+}
+
+func TestAutoRouteNormalization(t *testing.T) {
+	ulid := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	for _, tc := range []struct {
+		name  string
+		opts  []Option
+		tag   string
+		path  string
+		want  string
+		route bool
+	}{
+		{name: "disabled", path: "/users/42", want: "test_handler"},
+		{name: "default patterns", opts: []Option{WithAutoRouteNormalization()}, path: "/users/42", want: "/users/{id}", route: true},
+		{
+			name:  "custom patterns",
+			opts:  []Option{WithAutoRouteNormalization(ulid)},
+			path:  "/users/42/events/01ARZ3NDEKTSV4RRFFQ69G5FAV",
+			want:  "/users/42/events/{id}",
+			route: true,
+		},
+		{name: "route tag", opts: []Option{WithAutoRouteNormalization()}, tag: "/users/:id", path: "/users/42", want: "/users/:id", route: true},
+		{
+			name:  "trailing slash",
+			opts:  []Option{WithAutoRouteNormalization(), WithTrailingSlashNormalization(true)},
+			path:  "/users/42/",
+			want:  "/users/{id}",
+			route: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			if tc.tag != "" {
+				handler = WithRouteTag(tc.tag, handler)
+			}
+			opts := append([]Option{WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)))}, tc.opts...)
+			h := NewHandler(handler, "test_handler", opts...)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.want, spans[0].Name())
+			if tc.route {
+				assert.Equal(t, label.StringValue(tc.want), spans[0].Attributes()[semconv.HTTPRouteKey])
+			} else {
+				assert.NotContains(t, spans[0].Attributes(), semconv.HTTPRouteKey)
+			}
+		})
+	}
+}
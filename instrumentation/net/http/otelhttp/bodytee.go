@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// BodyDirection tells which body the bytes passed to the function of
+// WithBodyTee belong to.
+type BodyDirection int
+
+// Values of BodyDirection.
+const (
+	// RequestBodyDirection is the body of a request, read by the handler
+	// of the Handler or sent by the Transport.
+	RequestBodyDirection BodyDirection = iota
+	// ResponseBodyDirection is the body of a response, written by the
+	// handler of the Handler or read from the Transport.
+	ResponseBodyDirection
+)
+
+// String returns the name of d, "request" or "response".
+func (d BodyDirection) String() string {
+	if d == RequestBodyDirection {
+		return "request"
+	}
+	return "response"
+}
+
+// bodyTee returns the function passing the bytes of the body of a request
+// sent or served with ctx in direction d to tee, or nil if tee is nil.
+func bodyTee(ctx context.Context, tee func(context.Context, BodyDirection, []byte), d BodyDirection) func([]byte) {
+	if tee == nil {
+		return nil
+	}
+	return func(p []byte) {
+		if len(p) > 0 {
+			tee(ctx, d, p)
+		}
+	}
+}
+
+// teeBody passes the bytes read from a body to tee as they are read.
+type teeBody struct {
+	io.ReadCloser
+	tee func([]byte)
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.tee(p[:n])
+	return n, err
+}
+
+// teeResponseBody returns the body of res, with its bytes passed to tee as
+// they are read if tee is not nil. Bodies of 101 Switching Protocols
+// responses, which are the connection the protocol is switched to, are
+// returned as they are.
+func teeResponseBody(res *http.Response, tee func([]byte)) io.ReadCloser {
+	if tee == nil || res.Body == nil || res.Body == http.NoBody || res.StatusCode == http.StatusSwitchingProtocols {
+		return res.Body
+	}
+	return &teeBody{ReadCloser: res.Body, tee: tee}
+}
+
+// teeWriter passes the bytes written to w to tee before writing them.
+type teeWriter struct {
+	w   io.Writer
+	tee func([]byte)
+}
+
+func (w teeWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.tee(p[:n])
+	return n, err
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// bodyRecorder collects the bodies passed to the function of WithBodyTee.
+type bodyRecorder struct {
+	mu     sync.Mutex
+	bodies map[BodyDirection]*bytes.Buffer
+	traced bool
+}
+
+func (r *bodyRecorder) tee(ctx context.Context, d BodyDirection, p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bodies == nil {
+		r.bodies = map[BodyDirection]*bytes.Buffer{}
+	}
+	if r.bodies[d] == nil {
+		r.bodies[d] = new(bytes.Buffer)
+	}
+	r.bodies[d].Write(p)
+	r.traced = trace.SpanContextFromContext(ctx).IsValid()
+}
+
+func (r *bodyRecorder) body(d BodyDirection) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bodies[d] == nil {
+		return ""
+	}
+	return r.bodies[d].String()
+}
+
+func TestHandlerBodyTee(t *testing.T) {
+	for _, writerTo := range []bool{false, true} {
+		rec := &bodyRecorder{}
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body bytes.Buffer
+			var err error
+			if writerTo {
+				// io.Copy uses the WriteTo method of the body.
+				_, err = io.Copy(&body, r.Body)
+			} else {
+				_, err = body.ReadFrom(struct{ io.Reader }{r.Body})
+			}
+			assert.NoError(t, err)
+			_, _ = io.WriteString(w, "echo: ")
+			_, _ = w.Write(body.Bytes())
+		}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider()),
+			WithBodyTee(rec.tee),
+		)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello")))
+
+		assert.Equal(t, "echo: hello", w.Body.String())
+		assert.Equal(t, "hello", rec.body(RequestBodyDirection), "writerTo: %v", writerTo)
+		assert.Equal(t, "echo: hello", rec.body(ResponseBodyDirection))
+		assert.True(t, rec.traced)
+	}
+}
+
+func TestTransportBodyTee(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		_, _ = w.Write(bytes.ToUpper(body))
+	}))
+	defer ts.Close()
+
+	rec := &bodyRecorder{}
+	c := http.Client{Transport: NewTransport(http.DefaultTransport,
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithBodyTee(rec.tee),
+	)}
+	res, err := c.Post(ts.URL, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, "HELLO", string(body))
+	assert.Equal(t, "hello", rec.body(RequestBodyDirection))
+	assert.Equal(t, "HELLO", rec.body(ResponseBodyDirection))
+	assert.True(t, rec.traced)
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Values of cacheResult.
+const (
+	cacheUnknown int32 = iota
+	cacheMiss
+	cacheHit
+)
+
+// cacheResult records whether a request was answered by a cache, see
+// MarkCacheHit.
+type cacheResult struct {
+	result int32
+}
+
+func (c *cacheResult) mark(hit bool) {
+	result := cacheMiss
+	if hit {
+		result = cacheHit
+	}
+	atomic.StoreInt32(&c.result, result)
+}
+
+// get returns whether the request was answered by a cache, and whether this
+// is known. It returns false, false if c is nil.
+func (c *cacheResult) get() (hit, known bool) {
+	if c == nil {
+		return false, false
+	}
+	result := atomic.LoadInt32(&c.result)
+	return result == cacheHit, result != cacheUnknown
+}
+
+type cacheResultContextKeyType int
+
+const cacheResultContextKey cacheResultContextKeyType = 0
+
+func contextWithCacheResult(parent context.Context) (context.Context, *cacheResult) {
+	c := &cacheResult{}
+	return context.WithValue(parent, cacheResultContextKey, c), c
+}
+
+func cacheResultFromContext(ctx context.Context) *cacheResult {
+	c, _ := ctx.Value(cacheResultContextKey).(*cacheResult)
+	return c
+}
+
+// MarkCacheHit records whether the response to the request sent with ctx
+// was served from a cache, hit being true, or had to be fetched from the
+// server, hit being false. It is meant for caching RoundTrippers used as the
+// base RoundTripper of a Transport: they call it with the context of each
+// request they can cache, before returning its response.
+//
+//	func (c *cachingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+//		if res, ok := c.lookup(r); ok {
+//			otelhttp.MarkCacheHit(r.Context(), true)
+//			return res, nil
+//		}
+//		otelhttp.MarkCacheHit(r.Context(), false)
+//		...
+//	}
+//
+// The span of the request is recorded with the CacheHitKey attribute, and
+// the request is counted by the "http.client.cache.requests" instrument,
+// labeled with the CacheHitKey label, which tells cache hits apart from fast
+// servers. Requests the RoundTripper does not mark, like those it does not
+// cache, are neither. It returns false, and has no effect, if ctx is not the
+// context of a request sent by a Transport configured with WithCacheHits.
+func MarkCacheHit(ctx context.Context, hit bool) bool {
+	c := cacheResultFromContext(ctx)
+	if c == nil {
+		return false
+	}
+	c.mark(hit)
+	return true
+}
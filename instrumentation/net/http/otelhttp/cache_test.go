@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestMarkCacheHit(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	// Paths are cached after their first request, except /nocache.
+	cached := map[string]bool{}
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path != "/nocache" {
+			assert.True(t, MarkCacheHit(r.Context(), cached[r.URL.Path]))
+			cached[r.URL.Path] = true
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithCacheHits(true),
+	)
+
+	for _, path := range []string{"/a", "/a", "/nocache"} {
+		r, err := http.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Equal(t, label.BoolValue(false), spans[0].Attributes()[CacheHitKey])
+	assert.Equal(t, label.BoolValue(true), spans[1].Attributes()[CacheHitKey])
+	assert.NotContains(t, spans[2].Attributes(), CacheHitKey)
+
+	var hits []bool
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientCacheRequests {
+			assert.Equal(t, int64(1), m.Number.AsInt64())
+			assert.Equal(t, label.StringValue("example.com"), m.Labels[semconv.HTTPHostKey])
+			hits = append(hits, m.Labels[CacheHitKey].AsBool())
+		}
+	}
+	assert.Equal(t, []bool{false, true}, hits)
+}
+
+func TestMarkCacheHitUninstrumented(t *testing.T) {
+	assert.False(t, MarkCacheHit(context.Background(), true))
+}
+
+func TestMarkCacheHitDisabled(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		assert.False(t, MarkCacheHit(r.Context(), true))
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base, WithTracerProvider(oteltest.NewTracerProvider()), WithMeterProvider(meterProvider))
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, 0, countMeasurements(meterimpl, clientCacheRequests))
+}
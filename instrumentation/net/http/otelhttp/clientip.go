@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+)
+
+// parseTrustedProxies returns the networks of proxies, given as CIDR ranges
+// like "10.0.0.0/8" or as single IP addresses. Invalid proxies are reported
+// to the global error handler and skipped.
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		if strings.Contains(p, "/") {
+			if _, n, err := net.ParseCIDR(p); err == nil {
+				nets = append(nets, n)
+				continue
+			}
+		} else if ip := net.ParseIP(p); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		otel.Handle(fmt.Errorf("otelhttp: invalid trusted proxy %q", p))
+	}
+	return nets
+}
+
+// clientIPResolver derives the address of the client of a request that may
+// have gone through proxies from the headers they record it in.
+type clientIPResolver struct {
+	headers []string
+	// trusted are the networks of the trusted proxies, only the proxy
+	// requests are received from is trusted if it is empty.
+	trusted []*net.IPNet
+}
+
+// clientIP returns the address of the client of r, and whether it could be
+// determined. The addresses listed by the first of the headers r has,
+// followed by the address r was received from, form the chain of hops r
+// went through. The client is the rightmost address of the chain not
+// belonging to a trusted proxy, or the leftmost address if they all do.
+// Without trusted proxies, it is the rightmost address of the header, the
+// one the proxy r was received from recorded, as those on its left may have
+// been forged by the client. As an address that is not valid cannot be
+// trusted, the chain is not followed past it.
+func (c *clientIPResolver) clientIP(r *http.Request) (string, bool) {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	peerIP := net.ParseIP(peer)
+	if peerIP == nil {
+		return "", false
+	}
+	client := peerIP
+	if !c.isTrusted(client) {
+		return client.String(), true
+	}
+	chain := c.chain(r)
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseHop(chain[i])
+		if ip == nil {
+			break
+		}
+		client = ip
+		if len(c.trusted) == 0 || !c.isTrusted(ip) {
+			break
+		}
+	}
+	return client.String(), true
+}
+
+// chain returns the addresses listed by the first of the configured headers
+// r has, in order.
+func (c *clientIPResolver) chain(r *http.Request) []string {
+	for _, h := range c.headers {
+		values := r.Header.Values(h)
+		if len(values) == 0 {
+			continue
+		}
+		var chain []string
+		for _, v := range values {
+			chain = append(chain, strings.Split(v, ",")...)
+		}
+		return chain
+	}
+	return nil
+}
+
+// isTrusted returns whether ip belongs to a trusted proxy, or true if there
+// are none, for the proxy requests are received from.
+func (c *clientIPResolver) isTrusted(ip net.IP) bool {
+	if len(c.trusted) == 0 {
+		return true
+	}
+	for _, n := range c.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHop returns the IP address of a hop listed by a header, which may
+// include a port, or nil if it is not valid.
+func parseHop(hop string) net.IP {
+	hop = strings.TrimSpace(hop)
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+	return net.ParseIP(strings.Trim(hop, "[]"))
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	for _, tc := range []struct {
+		name    string
+		trusted bool
+		remote  string
+		headers map[string][]string
+		want    string
+	}{
+		{name: "no header", trusted: true, remote: "10.0.0.1:1234", want: "10.0.0.1"},
+		{name: "untrusted peer", trusted: true, remote: "203.0.113.7:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1"}}, want: "203.0.113.7"},
+		{name: "trusted peer", trusted: true, remote: "10.0.0.1:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1"}}, want: "198.51.100.1"},
+		{name: "spoofed chain", trusted: true, remote: "10.0.0.1:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1, 192.0.2.1"}}, want: "198.51.100.1"},
+		{name: "repeated header", trusted: true, remote: "10.0.0.1:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1", "10.0.0.2"}}, want: "198.51.100.1"},
+		{name: "all trusted", trusted: true, remote: "10.0.0.1:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, want: "10.0.0.3"},
+		{name: "invalid hop", trusted: true, remote: "10.0.0.1:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1, unknown, 10.0.0.2"}}, want: "10.0.0.2"},
+		{name: "ports and ipv6", trusted: true, remote: "[2001:db8::1]:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"[2001:db8:ffff::1]:80, 198.51.100.1:443"}}, want: "198.51.100.1"},
+		{name: "fallback header", trusted: true, remote: "10.0.0.1:1234",
+			headers: map[string][]string{"X-Real-Ip": {"198.51.100.1"}}, want: "198.51.100.1"},
+		{name: "no trusted proxies", remote: "203.0.113.7:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1, 203.0.113.8"}}, want: "203.0.113.8"},
+		{name: "forged leftmost", remote: "203.0.113.7:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"6.6.6.6, 198.51.100.1"}}, want: "198.51.100.1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &clientIPResolver{headers: []string{"X-Forwarded-For", "X-Real-IP"}}
+			if tc.trusted {
+				c.trusted = trusted
+			}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remote
+			for k, v := range tc.headers {
+				r.Header[k] = v
+			}
+			ip, ok := c.clientIP(r)
+			assert.True(t, ok)
+			assert.Equal(t, tc.want, ip)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "::1", "invalid", "10.0.0.0/33"})
+	require.Len(t, nets, 3)
+	assert.Equal(t, "10.0.0.0/8", nets[0].String())
+	assert.Equal(t, "192.0.2.1/32", nets[1].String())
+	assert.Equal(t, "::1/128", nets[2].String())
+}
+
+func TestHandlerClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "default", want: "198.51.100.1, 203.0.113.7"},
+		{name: "trusted proxy", opts: []Option{WithTrustedProxies("192.0.2.0/24")}, want: "203.0.113.7"},
+		{name: "untrusted proxy", opts: []Option{WithTrustedProxies("10.0.0.0/8")}, want: "192.0.2.1"},
+		{name: "headers", opts: []Option{WithClientIPFromHeaders([]string{"X-Real-IP"})}, want: "198.51.100.2"},
+		{name: "forged leftmost", opts: []Option{WithClientIPFromHeaders([]string{"X-Forwarded-For"})}, want: "203.0.113.7"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			opts := append([]Option{WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)))}, tc.opts...)
+			h := NewHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), "test_handler", opts...)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+			r.Header.Set("X-Real-IP", "198.51.100.2")
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, label.StringValue(tc.want), spans[0].Attributes()[semconv.HTTPClientIPKey])
+		})
+	}
+}
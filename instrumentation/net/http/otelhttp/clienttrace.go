@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// clientTrace returns the httptrace hooks the Transport installs to annotate
+// span with connection level details of the request to host, like the IP
+// address of the new connection made after resolving host, recorded with
+// the net.peer.ip attribute. The connections obtained for the request are
+// counted in held, if not nil.
+func (t *Transport) clientTrace(span trace.Span, host string, held *heldConns) *httptrace.ClientTrace {
+	// The dialer may connect to several of the resolved addresses at once,
+	// like to an IPv6 and an IPv4 one, only the first connection made is
+	// used.
+	var (
+		lookedUp  int32
+		connected sync.Once
+	)
+	return &httptrace.ClientTrace{
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			if info.Err == nil {
+				atomic.StoreInt32(&lookedUp, 1)
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil || atomic.LoadInt32(&lookedUp) == 0 {
+				return
+			}
+			connected.Do(func() {
+				if ip, _, err := net.SplitHostPort(addr); err == nil {
+					span.SetAttributes(semconv.NetPeerIPKey.String(ip))
+				}
+			})
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			span.SetAttributes(ConnectionReusedKey.Bool(info.Reused))
+			if held != nil {
+				if n, ok := held.acquire(info.Conn); ok {
+					span.SetAttributes(ConnectionConcurrentRequestsKey.Int(n))
+				}
+			}
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			// The server name only differs from the host if it was set with
+			// tls.Config.ServerName, it is empty if no SNI was sent, like
+			// for IP addresses.
+			if err == nil && state.ServerName != "" && state.ServerName != host {
+				span.SetAttributes(TLSServerNameKey.String(state.ServerName))
+			}
+		},
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestClientTraceComposesWithUserTrace(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(http.DefaultTransport, WithTracerProvider(provider))}
+
+	var userGotConn []bool
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			userGotConn = append(userGotConn, info.Reused)
+		},
+	})
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		require.NoError(t, err)
+		res, err := c.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	assert.Equal(t, []bool{false, true}, userGotConn)
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	assert.Equal(t, label.BoolValue(false), spans[0].Attributes()[ConnectionReusedKey])
+	assert.Equal(t, label.BoolValue(true), spans[1].Attributes()[ConnectionReusedKey])
+}
+
+func TestConnectionCounters(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	base := &http.Transport{}
+	defer base.CloseIdleConnections()
+
+	c := http.Client{Transport: NewTransport(base, WithMeterProvider(meterProvider), WithConnectionCounters(true))}
+	for i := 0; i < 3; i++ {
+		res, err := c.Get(ts.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	host := semconv.HTTPHostKey.String(ts.Listener.Addr().String())
+	counts := map[string]int64{}
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientConnectionsNew || m.Name == clientConnectionsReused {
+			assert.Equal(t, host.Value, m.Labels[host.Key])
+			counts[m.Name] += m.Number.AsInt64()
+		}
+	}
+	assert.Equal(t, map[string]int64{clientConnectionsNew: 1, clientConnectionsReused: 2}, counts)
+}
+
+func TestConnectionCountersDisabled(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(http.DefaultTransport, WithMeterProvider(meterProvider))}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		assert.NotEqual(t, clientConnectionsNew, m.Name)
+		assert.NotEqual(t, clientConnectionsReused, m.Name)
+	}
+}
+
+func TestTLSServerName(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		name       string
+		serverName string
+		url        string
+		want       string
+	}{
+		// The test certificate is valid for example.com.
+		{name: "overridden", serverName: "example.com", url: ts.URL, want: "example.com"},
+		// No SNI is sent for IP addresses.
+		{name: "ip address", url: ts.URL},
+		{name: "plaintext", url: "http://" + ts.Listener.Addr().String()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+			base := ts.Client().Transport.(*http.Transport).Clone()
+			base.TLSClientConfig.ServerName = tc.serverName
+			c := http.Client{Transport: NewTransport(base, WithTracerProvider(provider))}
+
+			res, err := c.Get(tc.url)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			if tc.want == "" {
+				assert.NotContains(t, spans[0].Attributes(), TLSServerNameKey)
+			} else {
+				assert.Equal(t, label.StringValue(tc.want), spans[0].Attributes()[TLSServerNameKey])
+			}
+		})
+	}
+}
+
+func TestTLSServerNameMatchingHost(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	_, s := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)).Tracer("test").Start(context.Background(), "test")
+	ct := (&Transport{}).clientTrace(s, "example.com", nil)
+	ct.TLSHandshakeDone(tls.ConnectionState{ServerName: "example.com"}, nil)
+	s.End()
+	require.Len(t, sr.Completed(), 1)
+	assert.NotContains(t, sr.Completed()[0].Attributes(), TLSServerNameKey)
+}
+
+func TestTLSALPN(t *testing.T) {
+	h1 := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer h1.Close()
+	h2 := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+
+	for _, tc := range []struct {
+		name string
+		ts   *httptest.Server
+		url  string
+		want string
+	}{
+		{name: "h2", ts: h2, url: h2.URL, want: "h2"},
+		// The client offers h2, which the server does not support.
+		{name: "http/1.1", ts: h1, url: h1.URL, want: "http/1.1"},
+		{name: "plaintext", ts: h1, url: "http://" + h1.Listener.Addr().String()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+			base := tc.ts.Client().Transport.(*http.Transport).Clone()
+			base.ForceAttemptHTTP2 = true
+			c := http.Client{Transport: NewTransport(base, WithTracerProvider(provider))}
+
+			res, err := c.Get(tc.url)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			if tc.want == "" {
+				assert.NotContains(t, spans[0].Attributes(), TLSALPNKey)
+			} else {
+				assert.Equal(t, label.StringValue(tc.want), spans[0].Attributes()[TLSALPNKey])
+			}
+		})
+	}
+}
+
+func TestPeerIP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	_, port, err := net.SplitHostPort(ts.Listener.Addr().String())
+	require.NoError(t, err)
+
+	sr := new(oteltest.StandardSpanRecorder)
+	c := http.Client{Transport: NewTransport(&http.Transport{}, WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))))}
+	get := func(url string) {
+		res, err := c.Get(url)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	// localhost may resolve to ::1 first, on which the server does not
+	// listen, the address connected to is recorded.
+	get("http://localhost:" + port)
+	// The connection is reused, no lookup is performed.
+	get("http://localhost:" + port)
+	// No lookup is performed for IP addresses.
+	get("http://127.0.0.1:" + port)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Equal(t, label.StringValue("127.0.0.1"), spans[0].Attributes()[semconv.NetPeerIPKey])
+	assert.NotContains(t, spans[1].Attributes(), semconv.NetPeerIPKey)
+	assert.NotContains(t, spans[2].Attributes(), semconv.NetPeerIPKey)
+}
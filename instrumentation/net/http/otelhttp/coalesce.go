@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// coalescing records whether a request was coalesced into another one, see
+// MarkCoalesced.
+type coalescing struct {
+	coalesced int32
+}
+
+func (c *coalescing) mark() {
+	atomic.StoreInt32(&c.coalesced, 1)
+}
+
+// isCoalesced returns whether the request was marked as coalesced. It
+// returns false if c is nil.
+func (c *coalescing) isCoalesced() bool {
+	return c != nil && atomic.LoadInt32(&c.coalesced) == 1
+}
+
+type coalescingContextKeyType int
+
+const coalescingContextKey coalescingContextKeyType = 0
+
+func contextWithCoalescing(parent context.Context) (context.Context, *coalescing) {
+	c := &coalescing{}
+	return context.WithValue(parent, coalescingContextKey, c), c
+}
+
+func coalescingFromContext(ctx context.Context) *coalescing {
+	c, _ := ctx.Value(coalescingContextKey).(*coalescing)
+	return c
+}
+
+// MarkCoalesced marks the request sent with ctx as a follower coalesced into
+// an identical request already in flight, its leader, whose response it
+// shares. It is meant for RoundTrippers deduplicating requests, like with
+// golang.org/x/sync/singleflight, used as the base RoundTripper of a
+// Transport: they call it with the context of the requests they do not send
+// themselves.
+//
+//	func (c *coalescingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+//		leader := false
+//		v, err, _ := c.group.Do(r.URL.String(), func() (interface{}, error) {
+//			leader = true
+//			return c.fetch(r)
+//		})
+//		if !leader {
+//			otelhttp.MarkCoalesced(r.Context())
+//		}
+//		...
+//	}
+//
+// The span of a follower is recorded with the CoalescedKey attribute, and
+// the follower is left out of the duration and request size metrics, so
+// that waiting for the leader does not count as another request. It returns
+// false, and has no effect, if ctx is not the context of a request sent by a
+// Transport configured with WithCoalescedRequests.
+func MarkCoalesced(ctx context.Context) bool {
+	c := coalescingFromContext(ctx)
+	if c == nil {
+		return false
+	}
+	c.mark()
+	return true
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestMarkCoalesced(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	// All requests but the first are coalesced into it.
+	var sent int32
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&sent, 1) > 1 {
+			assert.True(t, MarkCoalesced(r.Context()))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithCoalescedRequests(true),
+	)
+
+	for i := 0; i < 3; i++ {
+		r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.NotContains(t, spans[0].Attributes(), CoalescedKey)
+	for _, s := range spans[1:] {
+		assert.Equal(t, label.BoolValue(true), s.Attributes()[CoalescedKey])
+	}
+	assert.Equal(t, 1, countMeasurements(meterimpl, clientRequestDuration))
+}
+
+func TestMarkCoalescedUninstrumented(t *testing.T) {
+	assert.False(t, MarkCoalesced(context.Background()))
+}
+
+func TestMarkCoalescedDisabled(t *testing.T) {
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		assert.False(t, MarkCoalesced(r.Context()))
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	_, meterProvider := oteltest.NewMeterProvider()
+	tr := NewTransport(base, WithTracerProvider(oteltest.NewTracerProvider()), WithMeterProvider(meterProvider))
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+type cohortContextKeyType int
+
+const cohortContextKey cohortContextKeyType = 0
+
+// ContextWithCohort returns a copy of parent carrying name, the deployment
+// cohort, like "canary" or "shadow", the outbound requests sent with the
+// returned context belong to. The Transport records it on the spans of these
+// requests with the CohortKey attribute and, if name is one of the cohorts
+// configured with WithCohorts, labels their metrics with it, so that the
+// latencies and error rates of the cohorts can be compared side by side
+// while traffic is shifted to a new upstream.
+func ContextWithCohort(parent context.Context, name string) context.Context {
+	return context.WithValue(parent, cohortContextKey, name)
+}
+
+// CohortFromContext returns the cohort stored in ctx with
+// ContextWithCohort. The second return value is false if ctx carries none.
+func CohortFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(cohortContextKey).(string)
+	return name, ok
+}
+
+// cohortLabel returns the CohortKey label of the metrics of a request sent
+// with ctx, with cohorts other than those of cohorts recorded as "_OTHER",
+// and whether the request is labeled with it. Requests without a cohort,
+// and all requests if cohorts is empty, are not.
+func cohortLabel(ctx context.Context, cohorts map[string]bool) (label.KeyValue, bool) {
+	name, ok := CohortFromContext(ctx)
+	if !ok || len(cohorts) == 0 {
+		return label.KeyValue{}, false
+	}
+	if !cohorts[name] {
+		name = "_OTHER"
+	}
+	return CohortKey.String(name), true
+}
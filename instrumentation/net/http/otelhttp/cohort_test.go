@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestContextWithCohort(t *testing.T) {
+	_, ok := CohortFromContext(context.Background())
+	assert.False(t, ok)
+	cohort, ok := CohortFromContext(ContextWithCohort(context.Background(), "canary"))
+	assert.True(t, ok)
+	assert.Equal(t, "canary", cohort)
+}
+
+func TestTransportCohorts(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cohorts []string
+		want    []string
+	}{
+		{name: "spans only", want: []string{"", "", ""}},
+		{name: "labeled", cohorts: []string{"canary", "stable"}, want: []string{"canary", "_OTHER", ""}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			tr := NewTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}),
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithMeterProvider(meterProvider),
+				WithCohorts(tc.cohorts...),
+				WithOutcomeCounters(true),
+			)
+
+			for _, ctx := range []context.Context{
+				ContextWithCohort(context.Background(), "canary"),
+				ContextWithCohort(context.Background(), "shadow-42"),
+				context.Background(),
+			} {
+				r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/", nil)
+				require.NoError(t, err)
+				res, err := tr.RoundTrip(r)
+				require.NoError(t, err)
+				require.NoError(t, res.Body.Close())
+			}
+
+			spans := sr.Completed()
+			require.Len(t, spans, 3)
+			assert.Equal(t, label.StringValue("canary"), spans[0].Attributes()[CohortKey])
+			assert.Equal(t, label.StringValue("shadow-42"), spans[1].Attributes()[CohortKey])
+			assert.NotContains(t, spans[2].Attributes(), CohortKey)
+
+			got := map[string][]string{}
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientRequestDuration || m.Name == clientRequestsSuccess {
+					got[m.Name] = append(got[m.Name], m.Labels[CohortKey].AsString())
+				}
+			}
+			assert.Equal(t, map[string][]string{clientRequestDuration: tc.want, clientRequestsSuccess: tc.want}, got)
+		})
+	}
+}
@@ -26,22 +26,177 @@ const (
 	ReadErrorKey  = label.Key("http.read_error")  // If an error occurred while reading a request, the string of the error (io.EOF is not recorded)
 	WroteBytesKey = label.Key("http.wrote_bytes") // if anything was written to the response writer, the total number of bytes written
 	WriteErrorKey = label.Key("http.write_error") // if an error occurred while writing a reply, the string of the error (io.EOF is not recorded)
+
+	ClientErrorKey = label.Key("http.client.error") // if an outbound request failed, the string of the error, truncated to the configured length
+	ErrorTypeKey   = label.Key("error.type")        // the class of error of a failed outbound request, see WithErrorClassifier
+	StatusClassKey = label.Key("http.status_class") // the class of the status of the response to an outbound request, like "2xx", or "error" for requests that failed without a response
+
+	CodeFilepathKey = label.Key("code.filepath") // the source file of the code that issued an outbound request, see WithCallerLocation
+	CodeLineNoKey   = label.Key("code.lineno")   // the line number of the code that issued an outbound request, see WithCallerLocation
+
+	RequestBodySizeKey         = label.Key("http.request.body.size")         // the on-wire size of an outbound request body, if known
+	RequestUncompressedSizeKey = label.Key("http.request.uncompressed_size") // the uncompressed size of an outbound request body, if known, see ContextWithUncompressedSize
+
+	RequestContentTypeKey = label.Key("http.request.content_type") // the class of the Content-Type of a request, see WithContentTypeClassifier
+
+	ConnectionReusedKey = label.Key("http.client.connection.reused") // whether an outbound request was sent on a previously used connection
+	TLSServerNameKey    = label.Key("http.client.tls.server_name")   // the TLS server name (SNI) sent for an outbound request, if it differs from the host of its URL
+	TLSALPNKey          = label.Key("tls.alpn")                      // the application protocol negotiated with ALPN on the TLS connection of an outbound request, like "h2" or "http/1.1", if any
+
+	ServerAddressKey = label.Key("server.address")    // the host of the URL of an outbound request, if its Host header differs from it
+	RequestHostKey   = label.Key("http.request.host") // the Host header of an outbound request, if it differs from the host of its URL
+
+	ConnectionConcurrentRequestsKey = label.Key("http.client.connection.concurrent_requests") // the number of requests in flight on the connection of an outbound request when it was obtained, including the request, see WithConnectionConcurrency
+	NegotiatedProtocolKey           = label.Key("http.client.protocol")                       // the protocol of the response to an outbound request, like "HTTP/1.1" or "HTTP/2.0", see WithConnectionConcurrency
+	ProtocolExpectedKey             = label.Key("http.client.protocol.expected")              // the newest protocol of the responses received from a host before an outbound request was downgraded, see WithProtocolDowngradeDetection
+
+	ResponseAgeKey          = label.Key("http.response.header.age")           // the Age header of a response, see WithCacheDebug
+	ResponseXCacheKey       = label.Key("http.response.header.x_cache")       // the X-Cache header of a response, see WithCacheDebug
+	ResponseCacheControlKey = label.Key("http.response.header.cache_control") // the Cache-Control header of a response, see WithCacheDebug
+
+	SamplingPriorityKey = label.Key("sampling.priority") // the sampling hint of a request when starting its span, 1 to sample and 0 to drop, see WithSamplingHint
+
+	ResponseReadCountKey   = label.Key("http.response.body.read_count")    // the number of reads from a response body, see WithResponseReadStats
+	ResponseMaxReadSizeKey = label.Key("http.response.body.max_read_size") // the largest number of bytes returned by a single read from a response body, see WithResponseReadStats
+
+	SlowReadGapKey = label.Key("http.client.slow_read.gap") // the microseconds that passed between two reads from a response body, see WithSlowReadThreshold
+
+	RequestStallDurationKey = label.Key("http.server.request.stall.duration") // the microseconds a read from a request body blocked for, see WithRequestStallThreshold
+
+	APIVersionKey = label.Key("http.api.version") // the version of the API a request asks for, see WithAPIVersionExtractor
+
+	LatencyThresholdExceededKey = label.Key("http.client.latency_threshold_exceeded") // whether an outbound request lasted at least the threshold of WithLatencyThresholdTracing
+
+	ResponseChunkIndexKey = label.Key("http.response.chunk.index") // the index of a chunk of a response body, counted from 0, see WithStreamChunkEvents
+	ResponseChunkSizeKey  = label.Key("http.response.chunk.size")  // the number of bytes of a chunk of a response body, see WithStreamChunkEvents
+	ResponseChunkTotalKey = label.Key("http.response.chunk.total") // the number of bytes of a response body read up to the end of a chunk, see WithStreamChunkEvents
+
+	OriginatingRouteKey = label.Key("http.originating_route") // the route of the server endpoint that made an outbound request, see WithOriginatingRoute
+
+	RequestSendTimeKey     = label.Key("http.request.send_time")     // the time an outbound request was sent, in nanoseconds since the Unix epoch, see WithAbsoluteTimestamps
+	ResponseReceiveTimeKey = label.Key("http.response.receive_time") // the time the response headers to an outbound request were received, in nanoseconds since the Unix epoch, see WithAbsoluteTimestamps
+
+	TimeoutSourceKey = label.Key("http.client.timeout.source") // where the timeout bounding an outbound request comes from, one of the TimeoutSource values
+
+	ProxyKey = label.Key("http.client.proxy") // the address of the proxy an outbound request was sent through, see WithProxyAttribute
+
+	ResponseBodyKey          = label.Key("http.response.body")           // the beginning of the body of an error response, see WithErrorBodyCapture
+	ResponseBodyTruncatedKey = label.Key("http.response.body.truncated") // whether more of the body of an error response was read than recorded, see WithErrorBodyCapture
+
+	ResponseReasonPhraseKey = label.Key("http.response.reason_phrase") // the reason phrase of the status line of a response, see WithReasonPhrase
+
+	OperationKey = label.Key("http.client.operation") // the operation of an outbound request, see WithOperationExtractor
+
+	CoalescedKey = label.Key("http.client.coalesced") // whether an outbound request shared the response of an identical request in flight instead of being sent, see MarkCoalesced
+	CacheHitKey  = label.Key("http.client.cache.hit") // whether the response to an outbound request was served from a cache, see MarkCacheHit
+
+	NotModifiedKey = label.Key("http.client.not_modified") // whether the response to a conditional outbound request was a 304 Not Modified, see WithNotModifiedTracking
+
+	URLQueryKey    = label.Key("url.query")    // the query string of a request, with secret values redacted, see WithRecordQueryString
+	URLTemplateKey = label.Key("url.template") // the template of the path of an outbound request, see ContextWithRouteTemplate
+
+	TransactionIDKey = label.Key("transaction.id") // the business transaction a request belongs to, see ContextWithTransactionID
+
+	CohortKey = label.Key("deployment.cohort") // the deployment cohort an outbound request belongs to, see ContextWithCohort
+
+	QuantileKey = label.Key("quantile") // the quantile estimated by an observation of the http.client.duration.quantile instrument, see WithLatencySummary
+
+	ResendCountKey = label.Key("http.resend_count") // the number of times a request was resent before the current attempt, or in total on the span of the logical request, see WithPerAttemptSpans
+
+	RequestMethodOriginalKey = label.Key("http.request.method_original") // the method an inbound request was sent with, if overridden by a header, see WithMethodOverrideHeader
+
+	RequestAuthSchemeKey = label.Key("http.request.auth_scheme") // the authentication scheme of the Authorization header of an inbound request, see WithAuthSchemeAttribute
+
+	RequestHeadersSizeKey = label.Key("http.request.headers.size") // the summed length of the keys and values of the request header fields, see WithRequestHeadersSize
+
+	RequestReadDurationKey = label.Key("http.server.request.read.duration") // the microseconds from entering the handler to reading the end of the request body, if the handler read it to the end
+
+	ResponseWriteDurationKey = label.Key("http.server.response.write.duration") // the microseconds spent writing the response to the client, see WithResponseWriteDuration
+)
+
+// Values of the TimeoutSourceKey attribute.
+const (
+	TimeoutSourceContext    = "context"     // the deadline of the request context
+	TimeoutSourceClient     = "client"      // the http.Client.Timeout of the client sending the request, only known once it expires
+	TimeoutSourcePerRequest = "per_request" // the timeout configured with WithPerRequestTimeout
+	TimeoutSourceTransport  = "transport"   // a timeout of the base RoundTripper, like http.Transport.ResponseHeaderTimeout, only known once it expires
 )
 
 // Server HTTP metrics
 const (
-	RequestCount          = "http.server.request_count"           // Incoming request count total
-	RequestContentLength  = "http.server.request_content_length"  // Incoming request bytes total
-	ResponseContentLength = "http.server.response_content_length" // Incoming response bytes total
-	ServerLatency         = "http.server.duration"                // Incoming end to end duration, microseconds
+	RequestCount              = "http.server.request_count"           // Incoming request count total
+	RequestContentLength      = "http.server.request_content_length"  // Incoming request bytes total
+	RequestBodySize           = "http.server.request.body.size"       // Bytes read from the request body by the handler, per request, see WithRequestBodySize
+	ResponseContentLength     = "http.server.response_content_length" // Incoming response bytes total
+	ResponseBodySize          = "http.server.response.body.size"      // Bytes written to the response body by the handler, per request
+	ServerLatency             = "http.server.duration"                // Incoming end to end duration, microseconds
+	ServerHandlerLatency      = "http.server.handler.duration"        // Duration from entering to returning from the wrapped handler, microseconds, see WithHandlerDuration
+	ServerRequestReadDuration = "http.server.request.read.duration"   // Duration from entering the wrapped handler to reading the end of the request body, microseconds, see WithRequestReadDuration
+	ServerRejected            = "http.server.rejected"                // Incoming requests rejected by a limiter, see RecordRejection and WithRejectionCounter
+	ServerMissingParent       = "http.server.missing_parent"          // Incoming requests without a propagated trace context, see WithPropagationVerification
+	ServerActiveRequests      = "http.server.active_requests"         // Incoming requests being served, observed, see WithActiveRequestsGauge
+	ServerRequestStalls       = "http.server.request.stall"           // Reads from request bodies blocking longer than a threshold, see WithRequestStallThreshold
+	ServerSpansDropped        = "http.server.spans.dropped"           // Incoming requests served without a span as the span rate limit was reached, observed, see WithSpanRateLimit
+
+	ServerResponseWriteDuration = "http.server.response.write.duration" // Duration of the writes of the response to the client, microseconds, see WithResponseWriteDuration
 )
 
 // Client HTTP metric instrument names.
 const (
 	// clientRequestDuration is the name of the instrument that measures the duration of outbound HTTP requests.
 	clientRequestDuration = "http.client.duration"
+	// clientRequestSize is the name of the instrument that measures the on-wire size of outbound HTTP request bodies,
+	// that is the compressed size for bodies sent with a gzip, deflate, br or zstd Content-Encoding.
+	// The size of bodies of unknown length, like chunked uploads, is counted as they are sent.
+	clientRequestSize = "http.client.request.size"
+	// clientRequestUncompressedSize is the name of the instrument that measures the uncompressed size of outbound HTTP request bodies.
+	// For bodies sent with a gzip, deflate, br or zstd Content-Encoding it is only recorded if declared with ContextWithUncompressedSize.
+	clientRequestUncompressedSize = "http.client.request.uncompressed_size"
+	// clientResponseSize is the name of the instrument that measures the size of inbound HTTP response bodies,
+	// as read by the caller until the body is closed or read to the end, or their Content-Length if the
+	// metrics are recorded before the body is read, see WithRecordOnResponse.
+	clientResponseSize = "http.client.response.size"
+	// clientBodyLeaked is the name of the instrument that counts outbound HTTP response bodies that were never closed, see WithBodyLeakDetection.
+	clientBodyLeaked = "http.client.body.leaked"
+	// clientConnectionsNew is the name of the instrument that counts the new connections outbound HTTP requests were sent on, by host,
+	// see WithConnectionCounters.
+	clientConnectionsNew = "http.client.connections.new"
+	// clientConnectionsReused is the name of the instrument that counts the previously used connections outbound HTTP requests were sent on, by host.
+	// A high ratio of new connections points at connections not being kept alive, see http.Transport.MaxIdleConnsPerHost.
+	clientConnectionsReused = "http.client.connections.reused"
+	// clientRootRequests is the name of the instrument that counts the outbound HTTP requests sent outside of any span, which start a trace,
+	// by host, or by operation if WithOperationExtractor is used, see WithRootRequestsCounter.
+	clientRootRequests = "http.client.root_requests"
+	// clientConnectionConcurrentRequests is the name of the instrument that measures the number of requests in flight on the connection
+	// of outbound HTTP requests when it is obtained, by host, see WithConnectionConcurrency.
+	clientConnectionConcurrentRequests = "http.client.connection.concurrent_requests"
+	// clientCacheRequests is the name of the instrument that counts the outbound HTTP requests a caching RoundTripper answered from its cache
+	// or not, labeled with CacheHitKey and by host, or by operation if WithOperationExtractor is used, see WithCacheHits.
+	clientCacheRequests = "http.client.cache.requests"
+	// clientRequestsSuccess is the name of the instrument that counts the outbound HTTP requests that succeeded, and clientRequestsError
+	// the name of the one that counts those that failed, as classified by the ErrorClassifier, see WithOutcomeCounters. Both are
+	// labeled with StatusClassKey and by host, or by operation if WithOperationExtractor is used, clientRequestsError also with ErrorTypeKey.
+	// They count requests coalesced with MarkCoalesced, as each of them is answered.
+	clientRequestsSuccess = "http.client.requests.success"
+	clientRequestsError   = "http.client.requests.error"
+	// clientConditionalRequests is the name of the instrument that counts the conditional outbound HTTP requests, those with an
+	// If-None-Match or If-Modified-Since header, labeled with NotModifiedKey and by host, or by operation if WithOperationExtractor
+	// is used, see WithNotModifiedTracking.
+	clientConditionalRequests = "http.client.conditional_requests"
+	// clientOpenBodies is the name of the instrument that observes the number of outbound HTTP response bodies not yet closed
+	// or read to completion, see WithOpenBodiesGauge.
+	clientOpenBodies = "http.client.open_bodies"
+	// clientSpansDropped is the name of the instrument that observes the number of outbound HTTP requests sent without a span
+	// as the span rate limit was reached, see WithSpanRateLimit.
+	clientSpansDropped = "http.client.spans.dropped"
+	// clientRequestDurationQuantile is the name of the instrument that estimates quantiles of the duration of outbound HTTP requests, see WithLatencySummary.
+	clientRequestDurationQuantile = "http.client.duration.quantile"
 )
 
+// instrumentationErrors is the name of the instrument that counts the errors
+// encountered by the instrumentation itself.
+const instrumentationErrors = "otelhttp.instrumentation.errors"
+
 // Filter is a predicate used to determine whether a given http.request should
 // be traced. A Filter must return true if the request should be traced.
 type Filter func(*http.Request) bool
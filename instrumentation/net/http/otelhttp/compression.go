@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type uncompressedSizeContextKeyType int
+
+const uncompressedSizeContextKey uncompressedSizeContextKeyType = 0
+
+// ContextWithUncompressedSize returns a copy of parent that declares size as
+// the uncompressed size, in bytes, of the body of a request sent with it.
+// Clients compressing request payloads should use it so the Transport can
+// record the uncompressed size next to the on-wire size of the body.
+func ContextWithUncompressedSize(parent context.Context, size int64) context.Context {
+	return context.WithValue(parent, uncompressedSizeContextKey, size)
+}
+
+// compressedEncodings are the content codings for which the on-wire size of
+// a body is known to differ from its uncompressed size. For bodies sent with
+// any of them, the uncompressed size is only known if it is declared with
+// ContextWithUncompressedSize. For other codings, including identity, both
+// sizes are the on-wire size.
+var compressedEncodings = map[string]bool{
+	"gzip":    true,
+	"x-gzip":  true,
+	"deflate": true,
+	"br":      true,
+	"zstd":    true,
+}
+
+// isCompressed returns whether a body sent with the Content-Encoding header
+// value encoding is compressed.
+func isCompressed(encoding string) bool {
+	for _, e := range strings.Split(encoding, ",") {
+		if compressedEncodings[strings.ToLower(strings.TrimSpace(e))] {
+			return true
+		}
+	}
+	return false
+}
+
+// requestBodySizes returns the on-wire and the uncompressed size of the body
+// of the outbound request r. A size is -1 if it is unknown. The uncompressed
+// size is the one declared with ContextWithUncompressedSize or, for requests
+// that are not compressed, the on-wire size.
+func requestBodySizes(r *http.Request) (wire, uncompressed int64) {
+	wire = r.ContentLength
+	if wire == 0 && r.Body != nil && r.Body != http.NoBody {
+		// A zero ContentLength with a body means the length is unknown.
+		wire = -1
+	}
+
+	uncompressed = -1
+	if size, ok := r.Context().Value(uncompressedSizeContextKey).(int64); ok {
+		uncompressed = size
+	} else if !isCompressed(r.Header.Get("Content-Encoding")) {
+		uncompressed = wire
+	}
+	return wire, uncompressed
+}
@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestRequestBodySizes(t *testing.T) {
+	testCases := []struct {
+		name             string
+		body             string
+		encoding         string
+		declared         int64
+		wire, uncompress int64
+	}{
+		{name: "no body", wire: 0, uncompress: 0},
+		{name: "identity", body: "hello", wire: 5, uncompress: 5},
+		{name: "gzip without declared size", body: "hello", encoding: "gzip", wire: 5, uncompress: -1},
+		{name: "gzip with declared size", body: "hello", encoding: "gzip", declared: 42, wire: 5, uncompress: 42},
+		{name: "unknown encoding", body: "hello", encoding: "custom", wire: 5, uncompress: 5},
+		{name: "identity encoding", body: "hello", encoding: "identity", wire: 5, uncompress: 5},
+		{name: "x-gzip", body: "hello", encoding: "x-gzip", wire: 5, uncompress: -1},
+		{name: "deflate", body: "hello", encoding: "deflate", wire: 5, uncompress: -1},
+		{name: "br without declared size", body: "hello", encoding: "br", wire: 5, uncompress: -1},
+		{name: "br with declared size", body: "hello", encoding: "br", declared: 42, wire: 5, uncompress: 42},
+		{name: "zstd without declared size", body: "hello", encoding: "zstd", wire: 5, uncompress: -1},
+		{name: "zstd with declared size", body: "hello", encoding: "zstd", declared: 42, wire: 5, uncompress: 42},
+		{name: "upper case", body: "hello", encoding: "ZSTD", wire: 5, uncompress: -1},
+		{name: "stacked", body: "hello", encoding: "custom, br", wire: 5, uncompress: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.declared > 0 {
+				ctx = ContextWithUncompressedSize(ctx, tc.declared)
+			}
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			r, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/", body)
+			require.NoError(t, err)
+			if tc.encoding != "" {
+				r.Header.Set("Content-Encoding", tc.encoding)
+			}
+
+			wire, uncompressed := requestBodySizes(r)
+			assert.Equal(t, tc.wire, wire)
+			assert.Equal(t, tc.uncompress, uncompressed)
+		})
+	}
+}
+
+func TestRequestBodySizesUnknownLength(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://localhost/", struct{ *bytes.Buffer }{bytes.NewBufferString("hello")})
+	require.NoError(t, err)
+
+	wire, uncompressed := requestBodySizes(r)
+	assert.Equal(t, int64(-1), wire)
+	assert.Equal(t, int64(-1), uncompressed)
+}
+
+func TestTransportCompressedRequestSize(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithMeterProvider(meterProvider),
+	)}
+	ctx := ContextWithUncompressedSize(context.Background(), 100)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.URL, strings.NewReader("compressed"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.Int64Value(10), spans[0].Attributes()[RequestBodySizeKey])
+	assert.Equal(t, label.Int64Value(100), spans[0].Attributes()[RequestUncompressedSizeKey])
+
+	sizes := map[string]int64{}
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestSize || m.Name == clientRequestUncompressedSize {
+			sizes[m.Name] = m.Number.AsInt64()
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		clientRequestSize:             10,
+		clientRequestUncompressedSize: 100,
+	}, sizes)
+}
+
+func TestTransportStreamedRequestSizeAttributes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		encoding     string
+		uncompressed bool
+	}{
+		{name: "identity", uncompressed: true},
+		{name: "gzip", encoding: "gzip"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer ts.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+			c := http.Client{Transport: NewTransport(http.DefaultTransport, WithTracerProvider(provider))}
+
+			// A reader of unknown length is sent chunked.
+			body := struct{ io.Reader }{strings.NewReader("streamed body")}
+			req, err := http.NewRequest(http.MethodPost, ts.URL, body)
+			require.NoError(t, err)
+			if tc.encoding != "" {
+				req.Header.Set("Content-Encoding", tc.encoding)
+			}
+			res, err := c.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, label.Int64Value(13), spans[0].Attributes()[RequestBodySizeKey])
+			uncompressed, ok := spans[0].Attributes()[RequestUncompressedSizeKey]
+			assert.Equal(t, tc.uncompressed, ok)
+			if tc.uncompressed {
+				assert.Equal(t, label.Int64Value(13), uncompressed)
+			}
+		})
+	}
+}
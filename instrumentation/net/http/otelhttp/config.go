@@ -15,10 +15,15 @@
 package otelhttp
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"regexp"
+	"time"
 
 	"go.opentelemetry.io/contrib"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
@@ -26,6 +31,10 @@ import (
 
 const (
 	instrumentationName = "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	// defaultClientErrorMaxLength is the default maximum length of the
+	// ClientErrorKey attribute value.
+	defaultClientErrorMaxLength = 256
 )
 
 // config represents the configuration options available for the http.Handler
@@ -39,9 +48,81 @@ type config struct {
 	WriteEvent        bool
 	Filters           []Filter
 	SpanNameFormatter func(string, *http.Request) string
+	SpanEndHook       func(context.Context, trace.Span, *http.Request, *http.Response, error)
+	ServerSpanEndHook func(context.Context, trace.Span, *http.Request)
+	ClientErrorMaxLen int
+	CallerLocation    bool
+	CallerSkip        int
+	RequestTimeout    time.Duration
+	SlowReadThreshold time.Duration
+	StreamChunkEvents bool
+	ProtocolDowngrade bool
+	LatencyThreshold  time.Duration
+	RecordOnResponse  bool
+	CacheDebug        bool
+	NotModified       bool
+	SpanRateLimit     int
+	SamplingHint      func(*http.Request) SamplingHint
+	ResponseTrailers  []string
+	ReadStats         bool
+	OriginatingRoute  bool
+	BodyLeakDetection bool
+	OpenBodiesGauge   bool
+	ProxyAttribute    bool
+	ErrorBodyCapture  int
+	PerAttemptSpans   bool
+	HeadersSize       bool
+	ReasonPhrase      bool
+	RecordQueryString bool
+	SortedLabels      bool
+
+	ConnectionConcurrency bool
+	ConnectionCounters    bool
+	RootRequestsCounter   bool
+	CoalescedRequests     bool
+	CacheHits             bool
+	OutcomeCounters       bool
+	OutboundBaggage       []label.KeyValue
+	AbsoluteTimestamps    bool
+	RequestHeaderBaggage  []headerBaggageEntry
+	Cohorts               []string
+
+	HandlerDuration            bool
+	RejectionCounter           bool
+	RequestBodySize            bool
+	RequestReadDuration        bool
+	PropagationVerification    bool
+	ServeMuxPattern            bool
+	TrailingSlashNormalization bool
+	RouteIDPatterns            []*regexp.Regexp
+	ActiveRequestsGauge        bool
+	MethodOverrideHeader       string
+	TrustForwardedHeaders      bool
+	AuthSchemeAttribute        bool
+	ClientIPHeaders            []string
+	TrustedProxies             []*net.IPNet
+	RequestStallThreshold      time.Duration
+	ResponseWriteDuration      bool
+	APIVersionExtractor        func(*http.Request) string
+	APIVersionLabels           []string
+
+	LatencySummaryQuantiles []float64
+
+	ContentTypeClassifier     func(string) string
+	ErrorClassifier           func(*http.Response, error) string
+	ErrorStatusRules          []ErrorStatusRule
+	OperationExtractor        func(*http.Request) string
+	ContextAttributeExtractor func(context.Context) []label.KeyValue
+	QueryRedactor             func(string) bool
+	ObservationRecorders      map[string]metric.Float64ValueRecorder
+	BodyTee                   func(context.Context, BodyDirection, []byte)
 
 	TracerProvider trace.TracerProvider
 	MeterProvider  metric.MeterProvider
+
+	// GlobalMeterProvider is true when no MeterProvider was configured and
+	// the global one is used instead.
+	GlobalMeterProvider bool
 }
 
 // Option Interface used for setting *optional* config properties
@@ -60,9 +141,13 @@ func (o OptionFunc) Apply(c *config) {
 // newConfig creates a new config struct and applies opts to it.
 func newConfig(opts ...Option) *config {
 	c := &config{
-		Propagators:    otel.GetTextMapPropagator(),
-		TracerProvider: otel.GetTracerProvider(),
-		MeterProvider:  otel.GetMeterProvider(),
+		Propagators:       otel.GetTextMapPropagator(),
+		TracerProvider:    otel.GetTracerProvider(),
+		ClientErrorMaxLen: defaultClientErrorMaxLength,
+
+		ContentTypeClassifier: DefaultContentTypeClassifier,
+		ErrorClassifier:       DefaultErrorClassifier,
+		QueryRedactor:         DefaultQueryRedactor,
 	}
 	for _, opt := range opts {
 		opt.Apply(c)
@@ -72,12 +157,21 @@ func newConfig(opts ...Option) *config {
 		instrumentationName,
 		trace.WithInstrumentationVersion(contrib.SemVersion()),
 	)
-	c.Meter = c.MeterProvider.Meter(
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+		c.GlobalMeterProvider = true
+	}
+	c.Meter = newMeter(c.MeterProvider)
+
+	return c
+}
+
+// newMeter returns the Meter used by this instrumentation from provider.
+func newMeter(provider metric.MeterProvider) metric.Meter {
+	return provider.Meter(
 		instrumentationName,
 		metric.WithInstrumentationVersion(contrib.SemVersion()),
 	)
-
-	return c
 }
 
 // WithTracerProvider specifies a tracer provider to use for creating a tracer.
@@ -89,7 +183,8 @@ func WithTracerProvider(provider trace.TracerProvider) Option {
 }
 
 // WithMeterProvider specifies a meter provider to use for creating a meter.
-// If none is specified, the global provider is used.
+// If none is specified, the global provider is used, and a Transport
+// recreates its instruments when the global provider is replaced.
 func WithMeterProvider(provider metric.MeterProvider) Option {
 	return OptionFunc(func(cfg *config) {
 		cfg.MeterProvider = provider
@@ -170,3 +265,607 @@ func WithSpanNameFormatter(f func(operation string, r *http.Request) string) Opt
 		c.SpanNameFormatter = f
 	})
 }
+
+// WithSpanEndHook takes a function that will be called by the Transport right
+// before each client span ends, while it is still recording. The response is
+// nil and err is non-nil if the request failed.
+func WithSpanEndHook(f func(ctx context.Context, span trace.Span, r *http.Request, resp *http.Response, err error)) Option {
+	return OptionFunc(func(c *config) {
+		c.SpanEndHook = f
+	})
+}
+
+// WithServerSpanEndHook takes a function that will be called by the Handler
+// once the wrapped handler has returned and the span status has been set,
+// right before the server span ends.
+func WithServerSpanEndHook(f func(ctx context.Context, span trace.Span, r *http.Request)) Option {
+	return OptionFunc(func(c *config) {
+		c.ServerSpanEndHook = f
+	})
+}
+
+// WithClientErrorMaxLength configures the maximum length, in bytes, of the
+// error message recorded with the ClientErrorKey attribute. A value less than
+// or equal to zero disables truncation. The default is 256 bytes.
+func WithClientErrorMaxLength(n int) Option {
+	return OptionFunc(func(c *config) {
+		c.ClientErrorMaxLen = n
+	})
+}
+
+// WithCallerLocation configures the Transport to record the source location
+// of the code that issued each request, ignoring net/http and this package and
+// ascending skip more frames, with the CodeFilepathKey and CodeLineNoKey
+// attributes of sampled spans.
+func WithCallerLocation(skip int) Option {
+	return OptionFunc(func(c *config) {
+		c.CallerLocation = true
+		c.CallerSkip = skip
+	})
+}
+
+// WithPerRequestTimeout configures the Transport to bound each request whose
+// context has no deadline by the timeout d, which, like http.Client.Timeout,
+// covers reading the response body.
+func WithPerRequestTimeout(d time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.RequestTimeout = d
+	})
+}
+
+// WithContentTypeClassifier takes a function that maps the Content-Type header
+// of each request to the class recorded with the RequestContentTypeKey
+// attribute, from a small, fixed set. DefaultContentTypeClassifier is used if
+// f is nil.
+func WithContentTypeClassifier(f func(contentType string) string) Option {
+	return OptionFunc(func(c *config) {
+		if f == nil {
+			f = DefaultContentTypeClassifier
+		}
+		c.ContentTypeClassifier = f
+	})
+}
+
+// WithNotModifiedTracking configures the Transport to record whether the
+// responses to conditional requests were a 304 Not Modified with the
+// NotModifiedKey attribute, and to count conditional requests by it with the
+// "http.client.conditional_requests" metric.
+func WithNotModifiedTracking(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.NotModified = enabled
+	})
+}
+
+// WithCacheDebug configures the Transport to record the Age, X-Cache and
+// Cache-Control headers of responses with the ResponseAgeKey,
+// ResponseXCacheKey and ResponseCacheControlKey attributes.
+func WithCacheDebug(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.CacheDebug = enabled
+	})
+}
+
+// WithSamplingHint takes a function returning a hint passed to the sampler
+// when the span of each request is started, e.g. to always sample requests
+// with a debug header, see SampleHeaderHint. Samplers honor it through the
+// SamplingPriorityKey attribute.
+func WithSamplingHint(f func(*http.Request) SamplingHint) Option {
+	return OptionFunc(func(c *config) {
+		c.SamplingHint = f
+	})
+}
+
+// WithCapturedResponseTrailers configures the Transport to record the
+// response trailers with the given names as "http.response.trailer.<name>"
+// span attributes, once the response body has been read to the end.
+func WithCapturedResponseTrailers(names []string) Option {
+	return OptionFunc(func(c *config) {
+		c.ResponseTrailers = append(c.ResponseTrailers, names...)
+	})
+}
+
+// WithResponseReadStats configures the Transport to record the number of
+// reads from each response body and the size of the largest one as span
+// attributes, to spot undersized read buffers.
+func WithResponseReadStats(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ReadStats = enabled
+	})
+}
+
+// WithOriginatingRoute configures the Transport to record the route stored in
+// the context of a request with ContextWithOriginatingRoute as the
+// OriginatingRouteKey span attribute.
+func WithOriginatingRoute(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.OriginatingRoute = enabled
+	})
+}
+
+// WithBodyLeakDetection configures the Transport to count the response bodies
+// garbage collected without having been closed or read to the end with the
+// "http.client.body.leaked" metric. It sets a finalizer on every response.
+func WithBodyLeakDetection(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.BodyLeakDetection = enabled
+	})
+}
+
+// WithOpenBodiesGauge configures the Transport to report the number of
+// response bodies it returned that are not yet closed or read to the end with
+// the "http.client.open_bodies" observer.
+func WithOpenBodiesGauge(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.OpenBodiesGauge = enabled
+	})
+}
+
+// WithProxyAttribute configures the Transport to record the proxy each request
+// is sent through, as returned by the Proxy function of an *http.Transport
+// base RoundTripper, as the ProxyKey span attribute.
+func WithProxyAttribute(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ProxyAttribute = enabled
+	})
+}
+
+// WithErrorBodyCapture configures the Transport to record up to the first n
+// bytes read from the bodies of error responses with an
+// "http.response.error_body" span event.
+func WithErrorBodyCapture(n int) Option {
+	return OptionFunc(func(c *config) {
+		c.ErrorBodyCapture = n
+	})
+}
+
+// WithPropagationVerification configures the Handler to count the requests
+// received without a valid trace context with the ServerMissingParent metric,
+// labeled with the route set by WithRouteTag.
+func WithPropagationVerification(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.PropagationVerification = enabled
+	})
+}
+
+// WithLatencySummary configures the Transport to estimate the given
+// quantiles, between 0 and 1, of the duration of requests over each
+// collection interval, from a bounded sample, and to report them with the
+// "http.client.duration.quantile" observer.
+func WithLatencySummary(quantiles ...float64) Option {
+	return OptionFunc(func(c *config) {
+		c.LatencySummaryQuantiles = append(c.LatencySummaryQuantiles, quantiles...)
+	})
+}
+
+// WithPerAttemptSpans configures the Transport to trace the requests it sends
+// as logical requests, for a Transport wrapping a retrier that resends them
+// through another Transport of this package, which then starts a child span
+// per attempt.
+func WithPerAttemptSpans(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.PerAttemptSpans = enabled
+	})
+}
+
+// WithRequestHeadersSize configures the Handler and the Transport to record
+// the summed length of the keys and values of the request header fields as
+// the RequestHeadersSizeKey span attribute.
+func WithRequestHeadersSize(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.HeadersSize = enabled
+	})
+}
+
+// WithContextAttributeExtractor configures the Handler and the Transport to add
+// the attributes extractor returns for the context of each request to its
+// span, like the fields a structured logger carries in the context.
+func WithContextAttributeExtractor(extractor func(context.Context) []label.KeyValue) Option {
+	return OptionFunc(func(c *config) {
+		c.ContextAttributeExtractor = extractor
+	})
+}
+
+// WithServeMuxPattern configures the Handler to name spans after the path of
+// the Go 1.22 http.ServeMux pattern a request was routed with, and to record
+// it as the http.route attribute.
+func WithServeMuxPattern(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ServeMuxPattern = enabled
+	})
+}
+
+// WithErrorClassifier takes a function that maps the response or error of each
+// request sent by the Transport to the class of error recorded with the
+// ErrorTypeKey attribute and label, from a small, fixed set, or "" for
+// successes. DefaultErrorClassifier is used if f is nil.
+func WithErrorClassifier(f func(res *http.Response, err error) string) Option {
+	return OptionFunc(func(c *config) {
+		if f == nil {
+			f = DefaultErrorClassifier
+		}
+		c.ErrorClassifier = f
+	})
+}
+
+// WithTrailingSlashNormalization configures the Handler to remove the trailing
+// slash of the routes and URL paths it records, except for "/", so that
+// "/users" and "/users/" are reported as the same route.
+func WithTrailingSlashNormalization(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.TrailingSlashNormalization = enabled
+	})
+}
+
+// WithReasonPhrase configures the Transport to record the reason phrase of
+// the status line of responses as the ResponseReasonPhraseKey attribute.
+func WithReasonPhrase(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ReasonPhrase = enabled
+	})
+}
+
+// WithOperationExtractor configures the Transport to name spans and label
+// metrics after the operation f returns for each request, from a small, fixed
+// set, for RPC protocols over HTTP. Requests for which f returns "" are
+// recorded as without it.
+func WithOperationExtractor(f func(*http.Request) string) Option {
+	return OptionFunc(func(c *config) {
+		c.OperationExtractor = f
+	})
+}
+
+// WithRecordQueryString configures the Handler and the Transport to record the
+// query string of requests as the URLQueryKey attribute, with the values of
+// the parameters matched by the redactor of WithQueryRedactor replaced.
+func WithRecordQueryString(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.RecordQueryString = enabled
+	})
+}
+
+// WithQueryRedactor replaces the function deciding from the name of each
+// query parameter whether its value is redacted by WithRecordQueryString.
+// DefaultQueryRedactor is used if f is nil.
+func WithQueryRedactor(f func(name string) bool) Option {
+	return OptionFunc(func(c *config) {
+		if f == nil {
+			f = DefaultQueryRedactor
+		}
+		c.QueryRedactor = f
+	})
+}
+
+// WithResourceAttributesFromEnv adds the attributes EnvAttributes returns for
+// prefix, read once, to all the spans of the Handler or Transport.
+func WithResourceAttributesFromEnv(prefix string) Option {
+	return OptionFunc(func(c *config) {
+		if attrs := EnvAttributes(prefix); len(attrs) > 0 {
+			c.SpanStartOptions = append(c.SpanStartOptions, trace.WithAttributes(attrs...))
+		}
+	})
+}
+
+// WithConnectionConcurrency configures the Transport to record the number of
+// requests in flight on the connection of each request when it obtains it
+// with the ConnectionConcurrentRequestsKey attribute and the
+// "http.client.connection.concurrent_requests" metric.
+func WithConnectionConcurrency(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ConnectionConcurrency = enabled
+	})
+}
+
+// WithConnectionCounters configures the Transport to count the new and the
+// reused connections requests are sent on, by host, with the
+// "http.client.connections.new" and "http.client.connections.reused" metrics.
+func WithConnectionCounters(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ConnectionCounters = enabled
+	})
+}
+
+// WithRootRequestsCounter configures the Transport to count the requests sent
+// without a span or remote span context in their context with the
+// "http.client.root_requests" metric.
+func WithRootRequestsCounter(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.RootRequestsCounter = enabled
+	})
+}
+
+// WithCoalescedRequests configures the Transport to let its base RoundTripper
+// mark the requests it coalesces with MarkCoalesced, which has no effect
+// without it.
+func WithCoalescedRequests(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.CoalescedRequests = enabled
+	})
+}
+
+// WithCacheHits configures the Transport to count the requests its base
+// RoundTripper marks as served from its cache, or not, with MarkCacheHit with
+// the "http.client.cache.requests" metric.
+func WithCacheHits(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.CacheHits = enabled
+	})
+}
+
+// WithOutcomeCounters configures the Transport to count the requests that
+// succeeded and failed, as classified by WithErrorClassifier, with the
+// "http.client.requests.success" and "http.client.requests.error" metrics.
+func WithOutcomeCounters(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.OutcomeCounters = enabled
+	})
+}
+
+// WithAutoRouteNormalization configures the Handler to name spans after the URL
+// path of requests with the segments matched by patterns, or by
+// DefaultRouteIDPatterns if none are given, replaced with "{id}", for routers
+// that expose no route template.
+func WithAutoRouteNormalization(patterns ...*regexp.Regexp) Option {
+	return OptionFunc(func(c *config) {
+		if len(patterns) == 0 {
+			patterns = DefaultRouteIDPatterns()
+		}
+		c.RouteIDPatterns = patterns
+	})
+}
+
+// WithOutboundBaggage configures the Transport to add entries to the baggage it
+// injects in outbound requests, without overriding those of the request
+// context or exceeding the limits of the W3C Baggage header.
+func WithOutboundBaggage(entries map[string]string) Option {
+	return OptionFunc(func(c *config) {
+		c.OutboundBaggage = outboundBaggage(c.OutboundBaggage, entries)
+	})
+}
+
+// WithRequestHeaderToBaggage configures the Transport to copy the values of the
+// request headers named by the keys of mapping to the baggage entries named by
+// its values, without overriding those of the request context.
+func WithRequestHeaderToBaggage(mapping map[string]string) Option {
+	return OptionFunc(func(c *config) {
+		c.RequestHeaderBaggage = requestHeaderBaggage(c.RequestHeaderBaggage, mapping)
+	})
+}
+
+// WithSlowReadThreshold configures the Transport to add a
+// "http.client.slow_read" span event each time more than threshold passes
+// between two reads of a response body.
+func WithSlowReadThreshold(threshold time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.SlowReadThreshold = threshold
+	})
+}
+
+// WithSortedLabels configures the Handler and Transport to sort the labels of
+// the metrics they record by key, for backends sensitive to label order.
+func WithSortedLabels(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.SortedLabels = enabled
+	})
+}
+
+// WithActiveRequestsGauge configures the Handler to report the number of
+// requests it is serving with the ServerActiveRequests observer.
+func WithActiveRequestsGauge(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ActiveRequestsGauge = enabled
+	})
+}
+
+// WithRecordOnResponse configures the Transport to end the span and record the
+// metrics of a request when its response headers are received, leaving the
+// response body unwrapped, so the options instrumenting it have no effect.
+func WithRecordOnResponse(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.RecordOnResponse = enabled
+	})
+}
+
+// WithAbsoluteTimestamps configures the Transport to record the wall clock
+// times a request was sent and its response received with the
+// RequestSendTimeKey and ResponseReceiveTimeKey span attributes, to bound the
+// clock skew with the server.
+func WithAbsoluteTimestamps(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.AbsoluteTimestamps = enabled
+	})
+}
+
+// WithMethodOverrideHeader configures the Handler to record POST requests
+// overriding their method with header, like X-HTTP-Method-Override, with the
+// overriding method, see EffectiveMethod, and to label its metrics with it.
+func WithMethodOverrideHeader(header string) Option {
+	return OptionFunc(func(c *config) {
+		c.MethodOverrideHeader = header
+	})
+}
+
+// WithStreamChunkEvents configures the Transport to add a
+// "http.client.response.chunk" span event for each read of a response body,
+// to follow the progress of streaming responses.
+func WithStreamChunkEvents(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.StreamChunkEvents = enabled
+	})
+}
+
+// WithErrorStatusRules configures the Transport to set the status of the span
+// of each request with the first of rules matching it, instead of the default
+// status. DefaultErrorStatusRules returns a set of rules to start from.
+func WithErrorStatusRules(rules []ErrorStatusRule) Option {
+	return OptionFunc(func(c *config) {
+		c.ErrorStatusRules = rules
+	})
+}
+
+// WithProtocolDowngradeDetection configures the Transport to add a
+// "http.client.protocol_downgrade" span event to requests whose response uses
+// an older protocol than those received from the same host before.
+func WithProtocolDowngradeDetection(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ProtocolDowngrade = enabled
+	})
+}
+
+// WithLatencyThresholdTracing configures the Transport to mark the spans of
+// requests with whether they lasted at least threshold with the
+// LatencyThresholdExceededKey attribute, for the SpanProcessor of the
+// latencyfilter package.
+func WithLatencyThresholdTracing(threshold time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.LatencyThreshold = threshold
+	})
+}
+
+// WithTrustForwardedHeaders configures the Handler to record the scheme from
+// the Forwarded or X-Forwarded-Proto header of requests, see ForwardedScheme.
+// It must only be used if all requests go through proxies overwriting them.
+func WithTrustForwardedHeaders(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.TrustForwardedHeaders = enabled
+	})
+}
+
+// WithClientIPFromHeaders configures the Handler to record the address of the
+// client of requests, as listed by the first of headers they have, with the
+// http.client_ip attribute, skipping the hops of the proxies trusted with
+// WithTrustedProxies.
+func WithClientIPFromHeaders(headers []string) Option {
+	return OptionFunc(func(c *config) {
+		c.ClientIPHeaders = headers
+	})
+}
+
+// WithTrustedProxies configures the Handler to trust the hops of the headers of
+// WithClientIPFromHeaders belonging to proxies, given as CIDR ranges or IP
+// addresses.
+func WithTrustedProxies(proxies ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.TrustedProxies = parseTrustedProxies(proxies)
+	})
+}
+
+// WithRequestStallThreshold configures the Handler to add a
+// "http.server.request.stall" span event each time a read from a request body
+// blocks for longer than threshold, and to count them with the
+// ServerRequestStalls metric.
+func WithRequestStallThreshold(threshold time.Duration) Option {
+	return OptionFunc(func(c *config) {
+		c.RequestStallThreshold = threshold
+	})
+}
+
+// WithAPIVersionExtractor configures the Handler to record the API version f
+// returns for requests, like APIVersionFromAccept, with the APIVersionKey
+// attribute.
+func WithAPIVersionExtractor(f func(*http.Request) string) Option {
+	return OptionFunc(func(c *config) {
+		c.APIVersionExtractor = f
+	})
+}
+
+// WithAPIVersionLabels configures the Handler to label its metrics with the API
+// version of WithAPIVersionExtractor, recording versions other than versions
+// as "_OTHER".
+func WithAPIVersionLabels(versions ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.APIVersionLabels = versions
+	})
+}
+
+// WithObservationRecorder configures the Handler and Transport to record the
+// observations attached to requests under name with ContextWithObservation
+// with recorder when requests end.
+func WithObservationRecorder(name string, recorder metric.Float64ValueRecorder) Option {
+	return OptionFunc(func(c *config) {
+		if c.ObservationRecorders == nil {
+			c.ObservationRecorders = make(map[string]metric.Float64ValueRecorder)
+		}
+		c.ObservationRecorders[name] = recorder
+	})
+}
+
+// WithBodyTee configures the Handler and Transport to pass copies of the bytes
+// of request and response bodies to tee as they are read or written. tee is
+// called synchronously and must not block or retain p.
+func WithBodyTee(tee func(ctx context.Context, direction BodyDirection, p []byte)) Option {
+	return OptionFunc(func(c *config) {
+		c.BodyTee = tee
+	})
+}
+
+// WithCohorts configures the Transport to label its metrics with the cohort of
+// ContextWithCohort, recording cohorts other than cohorts as "_OTHER".
+func WithCohorts(cohorts ...string) Option {
+	return OptionFunc(func(c *config) {
+		c.Cohorts = cohorts
+	})
+}
+
+// WithSpanRateLimit configures the Handler and Transport to start at most
+// perSecond spans per second each, and to count the requests left without a
+// span.
+func WithSpanRateLimit(perSecond int) Option {
+	return OptionFunc(func(c *config) {
+		c.SpanRateLimit = perSecond
+	})
+}
+
+// WithAuthSchemeAttribute configures the Handler to record the scheme of the
+// Authorization header of requests, see AuthScheme, with the
+// RequestAuthSchemeKey attribute.
+func WithAuthSchemeAttribute(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.AuthSchemeAttribute = enabled
+	})
+}
+
+// WithResponseWriteDuration configures the Handler to record the time spent
+// writing the response with the ResponseWriteDurationKey attribute and the
+// ServerResponseWriteDuration metric.
+func WithResponseWriteDuration(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.ResponseWriteDuration = enabled
+	})
+}
+
+// WithHandlerDuration configures the Handler to record the time spent in the
+// wrapped handler with the ServerHandlerLatency metric, and to flush the
+// response once it returns so that ServerLatency includes writing it out to
+// the client. Responses without a Content-Length are then sent chunked.
+func WithHandlerDuration(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.HandlerDuration = enabled
+	})
+}
+
+// WithRejectionCounter configures the Handler to count the requests marked
+// with RecordRejection with the ServerRejected metric. RecordRejection has no
+// effect without it.
+func WithRejectionCounter(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.RejectionCounter = enabled
+	})
+}
+
+// WithRequestBodySize configures the Handler to record the bytes the wrapped
+// handler read from each request body with the RequestBodySize metric, which,
+// unlike the Content-Length, is also known for chunked requests.
+func WithRequestBodySize(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.RequestBodySize = enabled
+	})
+}
+
+// WithRequestReadDuration configures the Handler to record the time from
+// entering the wrapped handler to reading the end of the request body, to
+// tell slow uploads from slow processing.
+func WithRequestReadDuration(enabled bool) Option {
+	return OptionFunc(func(c *config) {
+		c.RequestReadDuration = enabled
+	})
+}
@@ -15,6 +15,7 @@
 package otelhttp
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -22,7 +23,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/oteltest"
 )
 
@@ -132,3 +135,88 @@ func TestSpanNameFormatter(t *testing.T) {
 		})
 	}
 }
+
+type logFieldsKey struct{}
+
+func TestContextAttributeExtractor(t *testing.T) {
+	extractor := func(ctx context.Context) []label.KeyValue {
+		fields, _ := ctx.Value(logFieldsKey{}).([]label.KeyValue)
+		return fields
+	}
+	withFields := func(r *http.Request) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), logFieldsKey{}, []label.KeyValue{label.String("request_id", "abc")}))
+	}
+
+	for _, fields := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		opts := []Option{
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithContextAttributeExtractor(extractor),
+		}
+
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler", opts...)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if fields {
+			r = withFields(r)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})
+		r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		if fields {
+			r = withFields(r)
+		}
+		res, err := NewTransport(base, opts...).RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		spans := sr.Completed()
+		require.Len(t, spans, 2)
+		for _, s := range spans {
+			if fields {
+				assert.Equal(t, label.StringValue("abc"), s.Attributes()["request_id"])
+			} else {
+				assert.NotContains(t, s.Attributes(), label.Key("request_id"))
+			}
+		}
+	}
+}
+
+func TestTransactionID(t *testing.T) {
+	for _, withID := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		opts := []Option{WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)))}
+
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler", opts...)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if withID {
+			r = r.WithContext(ContextWithTransactionID(r.Context(), "tx-1"))
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})
+		r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		if withID {
+			r = r.WithContext(ContextWithTransactionID(r.Context(), "tx-1"))
+		}
+		res, err := NewTransport(base, opts...).RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		spans := sr.Completed()
+		require.Len(t, spans, 2)
+		for _, s := range spans {
+			if withID {
+				assert.Equal(t, label.StringValue("tx-1"), s.Attributes()[TransactionIDKey])
+			} else {
+				assert.NotContains(t, s.Attributes(), TransactionIDKey)
+			}
+		}
+	}
+}
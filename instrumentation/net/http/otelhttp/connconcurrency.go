@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net"
+	"sync"
+)
+
+// maxConcurrencyConns bounds the connections a connConcurrency counts the
+// requests of. A request whose response body is never closed nor read to the
+// end is never released, neither is its connection, so the connections of
+// such requests would otherwise be counted for the lifetime of the
+// Transport, even after they are closed.
+const maxConcurrencyConns = 1024
+
+// connConcurrency counts the requests of a Transport in flight on each of
+// its connections, see WithConnectionConcurrency.
+type connConcurrency struct {
+	mu     sync.Mutex
+	active map[net.Conn]int
+}
+
+func newConnConcurrency() *connConcurrency {
+	return &connConcurrency{active: make(map[net.Conn]int)}
+}
+
+// count returns the number of requests in flight on conn, or 0 if conn is
+// not counted.
+func (c *connConcurrency) count(conn net.Conn) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active[conn]
+}
+
+// heldConns are the connections obtained for a request, which it holds
+// until it ends. A request obtains several connections if the base
+// RoundTripper retries it. A nil *heldConns holds nothing.
+type heldConns struct {
+	concurrency *connConcurrency
+	conns       []net.Conn
+}
+
+// acquire counts the request as in flight on conn and returns the number of
+// requests in flight on it, including this one. It returns false, counting
+// nothing, if conn is not counted yet and maxConcurrencyConns connections
+// already are.
+func (h *heldConns) acquire(conn net.Conn) (int, bool) {
+	c := h.concurrency
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.active[conn]; !ok && len(c.active) >= maxConcurrencyConns {
+		return 0, false
+	}
+	h.conns = append(h.conns, conn)
+	c.active[conn]++
+	return c.active[conn], true
+}
+
+// release counts the request as no longer in flight on the connections it
+// obtained.
+func (h *heldConns) release() {
+	if h == nil {
+		return
+	}
+	c := h.concurrency
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conn := range h.conns {
+		if c.active[conn]--; c.active[conn] <= 0 {
+			delete(c.active, conn)
+		}
+	}
+	h.conns = nil
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestConnectionConcurrency(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	c := http.Client{Transport: NewTransport(ts.Client().Transport,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithConnectionConcurrency(true),
+	)}
+
+	// The second request is sent once the first one is in flight, so that
+	// both are multiplexed on the same connection.
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			res, err := c.Get(ts.URL)
+			if assert.NoError(t, err) {
+				assert.NoError(t, res.Body.Close())
+			}
+		}()
+		<-received
+	}
+	close(release)
+	<-done
+	<-done
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	var concurrency []int64
+	for _, s := range spans {
+		assert.Equal(t, label.StringValue("HTTP/2.0"), s.Attributes()[NegotiatedProtocolKey])
+		concurrency = append(concurrency, s.Attributes()[ConnectionConcurrentRequestsKey].AsInt64())
+	}
+	sort.Slice(concurrency, func(i, j int) bool { return concurrency[i] < concurrency[j] })
+	assert.Equal(t, []int64{1, 2}, concurrency)
+
+	var recorded []int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientConnectionConcurrentRequests {
+			recorded = append(recorded, m.Number.AsInt64())
+		}
+	}
+	assert.Equal(t, []int64{1, 2}, recorded)
+
+	// Once the requests end, the connection carries none.
+	tr := c.Transport.(*instrumentedTransport)
+	tr.base.connConcurrency.mu.Lock()
+	assert.Empty(t, tr.base.connConcurrency.active)
+	tr.base.connConcurrency.mu.Unlock()
+}
+
+func TestConnectionConcurrencyDisabled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	sr := new(oteltest.StandardSpanRecorder)
+	c := http.Client{Transport: NewTransport(http.DefaultTransport,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+	)}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), ConnectionConcurrentRequestsKey)
+	assert.NotContains(t, spans[0].Attributes(), NegotiatedProtocolKey)
+}
+
+func TestConnectionConcurrencyBounded(t *testing.T) {
+	c := newConnConcurrency()
+	// The requests are never released, like the requests whose response body
+	// is never closed.
+	for i := 0; i < maxConcurrencyConns; i++ {
+		conn, _ := net.Pipe()
+		n, ok := (&heldConns{concurrency: c}).acquire(conn)
+		require.True(t, ok)
+		require.Equal(t, 1, n)
+	}
+
+	conn, _ := net.Pipe()
+	held := &heldConns{concurrency: c}
+	_, ok := held.acquire(conn)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.count(conn))
+	assert.Len(t, c.active, maxConcurrencyConns)
+	held.release()
+	assert.Len(t, c.active, maxConcurrencyConns)
+
+	// The connections already counted still are.
+	for counted := range c.active {
+		n, ok := (&heldConns{concurrency: c}).acquire(counted)
+		assert.True(t, ok)
+		assert.Equal(t, 2, n)
+		break
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"mime"
+	"strings"
+)
+
+// ContentTypeOther is the content type class of the media types
+// DefaultContentTypeClassifier does not know about.
+const ContentTypeOther = "other"
+
+// contentTypeClasses are the media types DefaultContentTypeClassifier
+// reports as their own class.
+var contentTypeClasses = map[string]bool{
+	"application/json":                  true,
+	"application/xml":                   true,
+	"application/x-www-form-urlencoded": true,
+	"application/octet-stream":          true,
+	"application/grpc":                  true,
+	"application/x-protobuf":            true,
+	"multipart/form-data":               true,
+	"multipart/mixed":                   true,
+	"text/plain":                        true,
+	"text/html":                         true,
+	"text/xml":                          true,
+	"text/csv":                          true,
+}
+
+// DefaultContentTypeClassifier maps a Content-Type header value to a small,
+// fixed set of classes, keeping the cardinality of the RequestContentTypeKey
+// attribute bounded. Parameters are dropped, structured syntax suffixes are
+// folded into their base type (application/vnd.api+json is reported as
+// application/json), and unknown or malformed media types are reported as
+// ContentTypeOther.
+func DefaultContentTypeClassifier(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ContentTypeOther
+	}
+	if contentTypeClasses[mediaType] {
+		return mediaType
+	}
+	if i := strings.LastIndex(mediaType, "+"); i != -1 && strings.HasPrefix(mediaType, "application/") {
+		if base := "application/" + mediaType[i+1:]; contentTypeClasses[base] {
+			return base
+		}
+	}
+	return ContentTypeOther
+}
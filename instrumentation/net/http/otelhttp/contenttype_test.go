@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestDefaultContentTypeClassifier(t *testing.T) {
+	testCases := map[string]string{
+		"application/json":                  "application/json",
+		"application/json; charset=utf-8":   "application/json",
+		"Application/JSON":                  "application/json",
+		"application/vnd.api+json":          "application/json",
+		"application/atom+xml":              "application/xml",
+		"multipart/form-data; boundary=abc": "multipart/form-data",
+		"text/plain":                        "text/plain",
+		"image/png":                         ContentTypeOther,
+		"application/vnd.custom+yaml":       ContentTypeOther,
+		"not a media type;;":                ContentTypeOther,
+	}
+	for contentType, expected := range testCases {
+		assert.Equal(t, expected, DefaultContentTypeClassifier(contentType), contentType)
+	}
+}
+
+func TestContentTypeAttribute(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	h := NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		"test_handler",
+		WithTracerProvider(provider),
+		WithContentTypeClassifier(func(contentType string) string {
+			if strings.HasPrefix(contentType, "image/") {
+				return "image"
+			}
+			return DefaultContentTypeClassifier(contentType)
+		}),
+	)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(http.DefaultTransport, WithTracerProvider(provider))}
+	res, err := c.Post(ts.URL, "image/png", strings.NewReader("png"))
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		switch span.SpanKind() {
+		case trace.SpanKindServer:
+			assert.Equal(t, label.StringValue("image"), span.Attributes()[RequestContentTypeKey])
+		case trace.SpanKindClient:
+			assert.Equal(t, label.StringValue(ContentTypeOther), span.Attributes()[RequestContentTypeKey])
+		}
+	}
+}
+
+func TestNilContentTypeClassifier(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	h := NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		"test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithContentTypeClassifier(nil),
+	)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue("application/json"), spans[0].Attributes()[RequestContentTypeKey])
+}
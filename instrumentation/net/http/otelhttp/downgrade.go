@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// protocolDowngradeEvent is the name of the span event added when the
+// response to a request uses an older protocol than responses received from
+// the same host before, see WithProtocolDowngradeDetection.
+const protocolDowngradeEvent = "http.client.protocol_downgrade"
+
+// protocolVersion is the version of the protocol of a response.
+type protocolVersion struct {
+	major, minor int
+	proto        string
+}
+
+func (v protocolVersion) less(o protocolVersion) bool {
+	return v.major < o.major || v.major == o.major && v.minor < o.minor
+}
+
+// protocolHistory keeps the newest protocol the responses from each host
+// used, to detect downgrades, see WithProtocolDowngradeDetection.
+type protocolHistory struct {
+	mu     sync.Mutex
+	newest map[string]protocolVersion
+}
+
+func newProtocolHistory() *protocolHistory {
+	return &protocolHistory{newest: map[string]protocolVersion{}}
+}
+
+// observe records the protocol of res, received from host, and returns the
+// newer protocol responses from host used before, if any.
+func (h *protocolHistory) observe(host string, res *http.Response) (protocolVersion, bool) {
+	v := protocolVersion{major: res.ProtoMajor, minor: res.ProtoMinor, proto: res.Proto}
+	if v.major == 0 {
+		// The base RoundTripper did not set the protocol.
+		return protocolVersion{}, false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	newest, ok := h.newest[host]
+	if !ok || newest.less(v) {
+		h.newest[host] = v
+		return protocolVersion{}, false
+	}
+	return newest, v.less(newest)
+}
+
+// recordDowngrade adds a protocolDowngradeEvent to span if res, received
+// from host, uses an older protocol than the responses from host before.
+func (h *protocolHistory) recordDowngrade(span trace.Span, host string, res *http.Response) {
+	if expected, downgraded := h.observe(host, res); downgraded {
+		span.AddEvent(protocolDowngradeEvent, trace.WithAttributes(
+			ProtocolExpectedKey.String(expected.proto),
+			NegotiatedProtocolKey.String(res.Proto),
+		))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestProtocolDowngradeDetection(t *testing.T) {
+	protos := map[string]int{"HTTP/1.1": 1, "HTTP/2.0": 2}
+	for _, enabled := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			proto := r.Header.Get("X-Proto")
+			major := protos[proto]
+			minor := 0
+			if major == 1 {
+				minor = 1
+			}
+			return &http.Response{StatusCode: http.StatusOK, Proto: proto, ProtoMajor: major, ProtoMinor: minor, Body: http.NoBody}, nil
+		})
+		tr := NewTransport(base,
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithProtocolDowngradeDetection(enabled),
+		)
+
+		for _, req := range []struct{ host, proto string }{
+			{"a.example.com", "HTTP/1.1"},
+			{"a.example.com", "HTTP/2.0"},
+			{"a.example.com", "HTTP/1.1"},
+			{"a.example.com", "HTTP/2.0"},
+			{"b.example.com", "HTTP/1.1"},
+			{"a.example.com", ""},
+		} {
+			r, err := http.NewRequest(http.MethodGet, "https://"+req.host, nil)
+			require.NoError(t, err)
+			r.Header.Set("X-Proto", req.proto)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+		}
+
+		spans := sr.Completed()
+		require.Len(t, spans, 6)
+		var downgraded []int
+		for i, s := range spans {
+			for _, e := range s.Events() {
+				assert.Equal(t, "http.client.protocol_downgrade", e.Name)
+				assert.Equal(t, label.StringValue("HTTP/2.0"), e.Attributes[ProtocolExpectedKey])
+				assert.Equal(t, label.StringValue("HTTP/1.1"), e.Attributes[NegotiatedProtocolKey])
+				downgraded = append(downgraded, i)
+			}
+		}
+		if enabled {
+			assert.Equal(t, []int{2}, downgraded)
+		} else {
+			assert.Empty(t, downgraded)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+)
+
+// envAttributeName matches the valid names of the environment variables
+// holding attributes, after their prefix: words of letters and digits
+// separated by one or two underscores.
+var envAttributeName = regexp.MustCompile(`^[A-Za-z0-9]+(__?[A-Za-z0-9]+)*$`)
+
+// EnvAttributes returns the attributes set by the environment variables
+// whose name starts with prefix, like "OTEL_HTTP_ATTR_". The rest of the
+// name of each variable is the attribute key, lower cased, with underscores
+// mapped to dots and double underscores to single ones, so that
+// OTEL_HTTP_ATTR_DEPLOYMENT_ENVIRONMENT sets deployment.environment and
+// OTEL_HTTP_ATTR_CLOUD_AVAILABILITY__ZONE sets cloud.availability_zone. The
+// value of the variable is the attribute value. Variables whose name does
+// not map to a valid key, like OTEL_HTTP_ATTR_ or OTEL_HTTP_ATTR_A__B_, are
+// reported to the global ErrorHandler and skipped. The attributes are sorted
+// by key.
+//
+// An empty prefix would export the whole environment of the process,
+// secrets included, so it is reported to the global ErrorHandler and no
+// attributes are returned.
+func EnvAttributes(prefix string) []label.KeyValue {
+	if prefix == "" {
+		otel.Handle(errors.New("otelhttp: empty prefix of the environment variables holding attributes"))
+		return nil
+	}
+	var attrs []label.KeyValue
+	for _, kv := range os.Environ() {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 || !strings.HasPrefix(kv[:i], prefix) {
+			continue
+		}
+		name, value := kv[len(prefix):i], kv[i+1:]
+		if !envAttributeName.MatchString(name) {
+			otel.Handle(fmt.Errorf("otelhttp: invalid attribute name in environment variable %s", kv[:i]))
+			continue
+		}
+		attrs = append(attrs, label.String(envAttributeKey(name), value))
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
+
+// envAttributeKey returns the attribute key for the valid environment
+// variable name suffix name.
+func envAttributeKey(name string) string {
+	words := strings.Split(strings.ToLower(name), "__")
+	for i, w := range words {
+		words[i] = strings.ReplaceAll(w, "_", ".")
+	}
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+// setenv sets the environment variables of vars and returns a function
+// unsetting them.
+func setenv(t *testing.T, vars map[string]string) func() {
+	for k, v := range vars {
+		require.NoError(t, os.Setenv(k, v))
+	}
+	return func() {
+		for k := range vars {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestEnvAttributes(t *testing.T) {
+	defer setenv(t, map[string]string{
+		"OTELHTTP_TEST_ATTR_DEPLOYMENT_ENVIRONMENT":   "production",
+		"OTELHTTP_TEST_ATTR_CLOUD_AVAILABILITY__ZONE": "eu-west-1a",
+		"OTELHTTP_TEST_ATTR_VERSION":                  "1.2.3",
+		"OTELHTTP_TEST_ATTR_":                         "empty name",
+		"OTELHTTP_TEST_ATTR_TRAILING_":                "trailing underscore",
+		"OTELHTTP_TEST_ATTR_A-B":                      "invalid character",
+		"OTELHTTP_TEST_OTHER":                         "other prefix",
+	})()
+
+	// The invalid names are reported to the global ErrorHandler, which can
+	// only be set once, so this is not verified.
+	assert.Equal(t, []label.KeyValue{
+		label.String("cloud.availability_zone", "eu-west-1a"),
+		label.String("deployment.environment", "production"),
+		label.String("version", "1.2.3"),
+	}, EnvAttributes("OTELHTTP_TEST_ATTR_"))
+}
+
+func TestEnvAttributesEmptyPrefix(t *testing.T) {
+	defer setenv(t, map[string]string{"OTELHTTP_TEST_SECRET": "hunter2"})()
+
+	// The empty prefix is reported to the global ErrorHandler, which can
+	// only be set once, so this is not verified.
+	assert.Empty(t, EnvAttributes(""))
+
+	sr := new(oteltest.StandardSpanRecorder)
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithResourceAttributesFromEnv(""),
+	)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), label.Key("otelhttp.test.secret"))
+}
+
+func TestResourceAttributesFromEnv(t *testing.T) {
+	unset := setenv(t, map[string]string{"OTELHTTP_TEST_ATTR_CLOUD_REGION": "eu-west-1"})
+
+	sr := new(oteltest.StandardSpanRecorder)
+	opts := []Option{
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithResourceAttributesFromEnv("OTELHTTP_TEST_ATTR_"),
+	}
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler", opts...)
+	tr := NewTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), opts...)
+	// The environment is only read when the Handler and Transport are
+	// created.
+	unset()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	for _, s := range spans {
+		assert.Equal(t, label.StringValue("eu-west-1"), s.Attributes()["cloud.region"])
+	}
+}
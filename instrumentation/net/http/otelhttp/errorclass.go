@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Error types reported by DefaultErrorClassifier for requests that failed
+// without a response.
+const (
+	ErrorTypeTimeout       = "timeout"        // the request timed out, see TimeoutSourceKey for the timeout that expired
+	ErrorTypeClientTimeout = "client_timeout" // the http.Client.Timeout of the client sending the request expired
+	ErrorTypeCanceled      = "canceled"       // the context of the request was canceled
+)
+
+// ErrClientTimeout matches, with errors.Is, the errors passed to the error
+// classifier for requests interrupted by the expiry of the
+// http.Client.Timeout of the client that sent them. The errors returned by
+// the Transport are left unchanged.
+//
+// The detection is best effort: the client bounds the context of the
+// request by its timeout too, and both expire at the same instant, so the
+// base RoundTripper may return before the client signals its timeout. Such
+// requests are then reported as timed out by their context deadline, see
+// ErrorTypeTimeout and TimeoutSourceContext.
+var ErrClientTimeout = errors.New("http.Client.Timeout exceeded")
+
+// clientTimeoutError wraps the error of a request interrupted by the
+// http.Client.Timeout of the client that sent it.
+type clientTimeoutError struct {
+	err error
+}
+
+func (e *clientTimeoutError) Error() string {
+	return e.err.Error() + " (" + ErrClientTimeout.Error() + ")"
+}
+
+func (e *clientTimeoutError) Unwrap() error { return e.err }
+
+func (e *clientTimeoutError) Is(target error) bool { return target == ErrClientTimeout }
+
+func (e *clientTimeoutError) Timeout() bool { return true }
+
+func (e *clientTimeoutError) Temporary() bool { return true }
+
+// clientTimedOut returns whether a request that failed with err was
+// interrupted by the http.Client.Timeout of the client that sent it. The
+// client signals its timeout by closing clientCancel, the Cancel channel it
+// sets on requests when the Timeout is set, since it cannot cancel them
+// through their context alone with a RoundTripper it does not know. When the
+// client timeout also bounds the context of the request, both expire at the
+// same time, so the detection is best effort for requests interrupted at
+// that very instant.
+func clientTimedOut(clientCancel <-chan struct{}, err error) bool {
+	if clientCancel == nil || err == nil {
+		return false
+	}
+	select {
+	case <-clientCancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultErrorClassifier returns the class of error of an outbound request
+// recorded with the ErrorTypeKey attribute and label. It is the status code of
+// responses with a 4xx or 5xx status, ErrorTypeClientTimeout for requests
+// interrupted by the http.Client.Timeout, as far as it is detected, see
+// ErrClientTimeout, ErrorTypeTimeout or
+// ErrorTypeCanceled for other requests that timed out or were canceled, like
+// with a context deadline, and the Go type of the error,
+// like "*net.OpError", for other requests that failed without a response. It
+// is empty for successful requests.
+func DefaultErrorClassifier(res *http.Response, err error) string {
+	switch {
+	case err == nil && res != nil && res.StatusCode >= http.StatusBadRequest:
+		return strconv.Itoa(res.StatusCode)
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrClientTimeout):
+		return ErrorTypeClientTimeout
+	case errors.Is(err, context.DeadlineExceeded) || isTimeout(err):
+		return ErrorTypeTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	default:
+		return fmt.Sprintf("%T", err)
+	}
+}
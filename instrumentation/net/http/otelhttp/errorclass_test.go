@@ -0,0 +1,295 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestDefaultErrorClassifier(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		res  *http.Response
+		err  error
+		want string
+	}{
+		{name: "success", res: &http.Response{StatusCode: http.StatusOK}, want: ""},
+		{name: "redirect", res: &http.Response{StatusCode: http.StatusFound}, want: ""},
+		{name: "client error", res: &http.Response{StatusCode: http.StatusNotFound}, want: "404"},
+		{name: "server error", res: &http.Response{StatusCode: http.StatusBadGateway}, want: "502"},
+		{name: "deadline", err: context.DeadlineExceeded, want: ErrorTypeTimeout},
+		{name: "net timeout", err: &net.OpError{Op: "dial", Err: timeoutError{}}, want: ErrorTypeTimeout},
+		{name: "canceled", err: context.Canceled, want: ErrorTypeCanceled},
+		{name: "client timeout", err: &clientTimeoutError{err: context.DeadlineExceeded}, want: ErrorTypeClientTimeout},
+		{name: "other", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: "*net.OpError"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, DefaultErrorClassifier(tc.res, tc.err))
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransportErrorClassifier(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}
+		res.Header.Set("X-Error-Code", "QUOTA_EXCEEDED")
+		return res, nil
+	})
+	calls := 0
+	classifier := func(res *http.Response, err error) string {
+		calls++
+		if res != nil && res.Header.Get("X-Error-Code") != "" {
+			return "quota"
+		}
+		return DefaultErrorClassifier(res, err)
+	}
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithErrorClassifier(classifier),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, 1, calls)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue("quota"), spans[0].Attributes()[ErrorTypeKey])
+
+	var found bool
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestDuration {
+			found = true
+			assert.Equal(t, label.StringValue("quota"), m.Labels[ErrorTypeKey])
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestTransportNilErrorClassifier(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithErrorClassifier(nil),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = tr.RoundTrip(r)
+	require.Error(t, err)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue(ErrorTypeCanceled), spans[0].Attributes()[ErrorTypeKey])
+}
+
+func TestTransportErrorClassifierFiltered(t *testing.T) {
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	calls := 0
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithFilter(func(*http.Request) bool { return false }),
+		WithErrorClassifier(func(res *http.Response, err error) string {
+			calls++
+			return DefaultErrorClassifier(res, err)
+		}),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = tr.RoundTrip(r)
+	require.Error(t, err)
+	assert.Equal(t, 0, calls)
+}
+
+func TestTransportErrorTypeOnFailure(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = tr.RoundTrip(r)
+	require.Error(t, err)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue(ErrorTypeCanceled), spans[0].Attributes()[ErrorTypeKey])
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestDuration {
+			assert.Equal(t, label.StringValue(ErrorTypeCanceled), m.Labels[ErrorTypeKey])
+		}
+	}
+}
+
+func TestTransportErrorTypeOnSuccess(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base, WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))))
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), ErrorTypeKey)
+}
+
+func TestTransportClientTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		client     *http.Client
+		ctx        func() (context.Context, context.CancelFunc)
+		wantType   string
+		wantSource string
+	}{
+		{
+			name:   "client timeout",
+			client: &http.Client{Timeout: 10 * time.Millisecond},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			wantType:   ErrorTypeClientTimeout,
+			wantSource: TimeoutSourceClient,
+		},
+		{
+			name:   "context deadline",
+			client: &http.Client{},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			wantType:   ErrorTypeTimeout,
+			wantSource: TimeoutSourceContext,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				// Like http.Transport, give up once the client or the
+				// context cancels the request.
+				select {
+				case <-r.Cancel:
+					return nil, errors.New("net/http: request canceled")
+				case <-r.Context().Done():
+					if r.Cancel != nil {
+						// Let the client time out too, as both expire
+						// at the same time.
+						<-r.Cancel
+					}
+					return nil, r.Context().Err()
+				}
+			})
+			tc.client.Transport = NewTransport(base,
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithMeterProvider(meterProvider),
+			)
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+			_, err = tc.client.Do(r)
+			require.Error(t, err)
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, label.StringValue(tc.wantType), spans[0].Attributes()[ErrorTypeKey])
+			assert.Equal(t, label.StringValue(tc.wantSource), spans[0].Attributes()[TimeoutSourceKey])
+			assert.Equal(t, codes.Error, spans[0].StatusCode())
+			assert.Equal(t, tc.wantType, spans[0].StatusMessage())
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientRequestDuration {
+					assert.Equal(t, label.StringValue(tc.wantType), m.Labels[ErrorTypeKey])
+				}
+			}
+		})
+	}
+}
+
+func TestTransportClientTimeoutRealTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	sr := new(oteltest.StandardSpanRecorder)
+	c := &http.Client{
+		Timeout:   10 * time.Millisecond,
+		Transport: NewTransport(http.DefaultTransport, WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)))),
+	}
+	_, err := c.Get(ts.URL)
+	require.Error(t, err)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].StatusCode())
+	// The client timeout and the context deadline it sets expire at the
+	// same instant, the client timeout is only detected if it is signaled
+	// before http.Transport returns, see ErrClientTimeout.
+	attrs := spans[0].Attributes()
+	switch attrs[ErrorTypeKey] {
+	case label.StringValue(ErrorTypeClientTimeout):
+		assert.Equal(t, label.StringValue(TimeoutSourceClient), attrs[TimeoutSourceKey])
+	case label.StringValue(ErrorTypeTimeout):
+		assert.Equal(t, label.StringValue(TimeoutSourceContext), attrs[TimeoutSourceKey])
+	default:
+		t.Errorf("unexpected error type %v", attrs[ErrorTypeKey].Emit())
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrorStatusRule maps the outcome of requests sent by the Transport to the
+// status of their span, see WithErrorStatusRules.
+type ErrorStatusRule struct {
+	// Match reports whether the rule applies to a request, given its
+	// response or error. The error of requests interrupted by the
+	// http.Client.Timeout matches ErrClientTimeout, as for the error
+	// classifier. It must not read the response body.
+	Match func(res *http.Response, err error) bool
+	// Code is the status code of the span of the requests matched.
+	Code codes.Code
+	// Description is the status description of the span of the requests
+	// matched. If empty, the description the Transport sets without the
+	// rule is kept for spans with the codes.Error code.
+	Description string
+}
+
+// MatchError returns an ErrorStatusRule.Match function matching the requests
+// that failed with an error matching target, with errors.Is.
+func MatchError(target error) func(*http.Response, error) bool {
+	return func(_ *http.Response, err error) bool {
+		return err != nil && errors.Is(err, target)
+	}
+}
+
+// MatchStatusCode returns an ErrorStatusRule.Match function matching the
+// requests whose response has one of the status codes.
+func MatchStatusCode(statusCodes ...int) func(*http.Response, error) bool {
+	return func(res *http.Response, err error) bool {
+		if err != nil || res == nil {
+			return false
+		}
+		for _, code := range statusCodes {
+			if res.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DefaultErrorStatusRules returns a set of rules for WithErrorStatusRules
+// leaving the status of the spans of requests canceled through their context
+// unset, as they are usually abandoned by the caller rather than failed, and
+// of those answered with 404 Not Found unset too, as a missing resource is
+// often an expected answer. Other requests get the status the Transport sets
+// without rules.
+func DefaultErrorStatusRules() []ErrorStatusRule {
+	return []ErrorStatusRule{
+		{Match: MatchError(context.Canceled), Code: codes.Unset},
+		{Match: MatchStatusCode(http.StatusNotFound), Code: codes.Unset},
+	}
+}
+
+// errorStatus returns the span status for the outcome of a request, its
+// response or error, given by the first of rules matching it, and whether
+// one does. It returns code and description if none does.
+func errorStatus(rules []ErrorStatusRule, res *http.Response, err error, code codes.Code, description string) (codes.Code, string, bool) {
+	for _, rule := range rules {
+		if rule.Match == nil || !rule.Match(res, err) {
+			continue
+		}
+		switch {
+		case rule.Description != "":
+			return rule.Code, rule.Description, true
+		case rule.Code == codes.Error:
+			return rule.Code, description, true
+		default:
+			return rule.Code, "", true
+		}
+	}
+	return code, description, false
+}
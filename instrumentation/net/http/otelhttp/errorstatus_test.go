@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestErrorStatusRules(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	for _, tc := range []struct {
+		name     string
+		rules    []ErrorStatusRule
+		status   int
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{name: "no rules, canceled", err: context.Canceled, wantCode: codes.Error, wantMsg: ErrorTypeCanceled},
+		{name: "no rules, not found", status: http.StatusNotFound, wantCode: codes.Error},
+		{name: "default, canceled", rules: DefaultErrorStatusRules(), err: context.Canceled, wantCode: codes.Unset},
+		{name: "default, not found", rules: DefaultErrorStatusRules(), status: http.StatusNotFound, wantCode: codes.Unset},
+		{name: "default, server error", rules: DefaultErrorStatusRules(), status: http.StatusBadGateway, wantCode: codes.Error},
+		{name: "default, other error", rules: DefaultErrorStatusRules(), err: errBackend, wantCode: codes.Error, wantMsg: "*errors.errorString"},
+		{
+			name: "description",
+			rules: []ErrorStatusRule{
+				{Match: MatchError(errBackend), Code: codes.Error, Description: "backend down"},
+				{Match: MatchError(errBackend), Code: codes.Unset},
+			},
+			err:      errBackend,
+			wantCode: codes.Error,
+			wantMsg:  "backend down",
+		},
+		{
+			name:     "success as error",
+			rules:    []ErrorStatusRule{{Match: MatchStatusCode(http.StatusAccepted), Code: codes.Error, Description: "not done"}},
+			status:   http.StatusAccepted,
+			wantCode: codes.Error,
+			wantMsg:  "not done",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &http.Response{StatusCode: tc.status, Body: http.NoBody}, nil
+			})
+			tr := NewTransport(base,
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithErrorStatusRules(tc.rules),
+			)
+
+			r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+			if res, err := tr.RoundTrip(r); err == nil {
+				require.NoError(t, res.Body.Close())
+			}
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.wantCode, spans[0].StatusCode())
+			if tc.wantMsg != "" {
+				assert.Equal(t, tc.wantMsg, spans[0].StatusMessage())
+			}
+		})
+	}
+}
@@ -15,8 +15,15 @@
 package otelhttp
 
 import (
+	"bufio"
+	"context"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/felixge/httpsnoop"
@@ -27,6 +34,7 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/unit"
 )
 
 var _ http.Handler = &Handler{}
@@ -47,8 +55,37 @@ type Handler struct {
 	writeEvent        bool
 	filters           []Filter
 	spanNameFormatter func(string, *http.Request) string
+	spanEndHook       func(context.Context, trace.Span, *http.Request)
+	contentTypeClass  func(string) string
+	samplingHint      func(*http.Request) SamplingHint
+	handlerDuration   bool
+	countRejections   bool
+	requestBodySize   bool
+	readDuration      bool
+	verifyPropagation bool
+	headersSize       bool
+	serveMuxPattern   bool
+	routeIDPatterns   []*regexp.Regexp
+	trimTrailingSlash bool
+	contextAttributes func(context.Context) []label.KeyValue
+	recordQuery       bool
+	queryRedactor     func(string) bool
+	sortedLabels      bool
+	methodOverride    string
+	trustForwarded    bool
+	authScheme        bool
+	clientIP          *clientIPResolver
+	stallThreshold    time.Duration
+	writeDuration     bool
+	apiVersion        func(*http.Request) string
+	apiVersionLabels  map[string]bool
+	obsRecorders      map[string]metric.Float64ValueRecorder
+	bodyTee           func(context.Context, BodyDirection, []byte)
+	spanLimiter       *spanLimiter
+	activeRequests    *int64 // the requests being served, if WithActiveRequestsGauge is used
 	counters          map[string]metric.Int64Counter
 	valueRecorders    map[string]metric.Int64ValueRecorder
+	errorHandler      errorHandler
 }
 
 func defaultHandlerFormatter(operation string, _ *http.Request) string {
@@ -57,6 +94,11 @@ func defaultHandlerFormatter(operation string, _ *http.Request) string {
 
 // NewHandler wraps the passed handler, functioning like middleware, in a span
 // named after the operation and with any provided Options.
+//
+// A Handler serving a request already served by another Handler, like when
+// it is applied twice in a middleware stack, passes it through to the passed
+// handler, so each request only gets one span and one set of metrics, those
+// of the outermost Handler.
 func NewHandler(handler http.Handler, operation string, opts ...Option) http.Handler {
 	h := Handler{
 		handler:   handler,
@@ -84,34 +126,189 @@ func (h *Handler) configure(c *config) {
 	h.writeEvent = c.WriteEvent
 	h.filters = c.Filters
 	h.spanNameFormatter = c.SpanNameFormatter
+	h.spanEndHook = c.ServerSpanEndHook
+	h.contentTypeClass = c.ContentTypeClassifier
+	h.samplingHint = c.SamplingHint
+	h.handlerDuration = c.HandlerDuration
+	h.countRejections = c.RejectionCounter
+	h.requestBodySize = c.RequestBodySize
+	h.readDuration = c.RequestReadDuration
+	h.verifyPropagation = c.PropagationVerification
+	h.headersSize = c.HeadersSize
+	h.serveMuxPattern = c.ServeMuxPattern
+	h.routeIDPatterns = c.RouteIDPatterns
+	h.trimTrailingSlash = c.TrailingSlashNormalization
+	h.contextAttributes = c.ContextAttributeExtractor
+	h.recordQuery = c.RecordQueryString
+	h.sortedLabels = c.SortedLabels
+	h.methodOverride = c.MethodOverrideHeader
+	h.trustForwarded = c.TrustForwardedHeaders
+	h.authScheme = c.AuthSchemeAttribute
+	// The client IP is only resolved if WithClientIPFromHeaders or
+	// WithTrustedProxies is used.
+	if len(c.ClientIPHeaders) > 0 || len(c.TrustedProxies) > 0 {
+		h.clientIP = &clientIPResolver{headers: c.ClientIPHeaders, trusted: c.TrustedProxies}
+		if len(h.clientIP.headers) == 0 {
+			h.clientIP.headers = []string{"X-Forwarded-For", "X-Real-IP"}
+		}
+	}
+	h.stallThreshold = c.RequestStallThreshold
+	h.writeDuration = c.ResponseWriteDuration
+	h.apiVersion = c.APIVersionExtractor
+	h.obsRecorders = c.ObservationRecorders
+	h.bodyTee = c.BodyTee
+	h.spanLimiter = newSpanLimiter(c.SpanRateLimit)
+	if len(c.APIVersionLabels) > 0 {
+		h.apiVersionLabels = make(map[string]bool, len(c.APIVersionLabels))
+		for _, v := range c.APIVersionLabels {
+			h.apiVersionLabels[v] = true
+		}
+	}
+	if c.ActiveRequestsGauge {
+		h.activeRequests = new(int64)
+	}
+	h.queryRedactor = c.QueryRedactor
 }
 
-func handleErr(err error) {
-	if err != nil {
-		otel.Handle(err)
+// errorHandler passes the errors encountered by the instrumentation, like
+// failures to create instruments, to the global ErrorHandler and counts them
+// with the otelhttp.instrumentation.errors instrument so operators can alert
+// when the instrumentation is silently degrading.
+type errorHandler struct {
+	counter metric.Int64Counter
+}
+
+// newErrorHandler returns an errorHandler counting errors with an instrument
+// created from meter.
+func newErrorHandler(meter metric.Meter) errorHandler {
+	var eh errorHandler
+	var err error
+	eh.counter, err = meter.NewInt64Counter(
+		instrumentationErrors,
+		metric.WithDescription("counts the errors encountered by the HTTP instrumentation"),
+	)
+	eh.handleErr(err)
+	return eh
+}
+
+func (eh errorHandler) handleErr(err error) {
+	if err == nil {
+		return
 	}
+	otel.Handle(err)
+
+	// Counting must never take the instrumented application down, even if
+	// the meter is broken.
+	defer func() {
+		if r := recover(); r != nil {
+			otel.Handle(fmt.Errorf("otelhttp: failed to count instrumentation error: %v", r))
+		}
+	}()
+	eh.counter.Add(context.Background(), 1)
 }
 
 func (h *Handler) createMeasures() {
 	h.counters = make(map[string]metric.Int64Counter)
 	h.valueRecorders = make(map[string]metric.Int64ValueRecorder)
+	h.errorHandler = newErrorHandler(h.meter)
 
 	requestBytesCounter, err := h.meter.NewInt64Counter(RequestContentLength)
-	handleErr(err)
+	h.errorHandler.handleErr(err)
 
 	responseBytesCounter, err := h.meter.NewInt64Counter(ResponseContentLength)
-	handleErr(err)
+	h.errorHandler.handleErr(err)
+
+	responseBodySizeMeasure, err := h.meter.NewInt64ValueRecorder(ResponseBodySize, metric.WithUnit(unit.Bytes))
+	h.errorHandler.handleErr(err)
 
 	serverLatencyMeasure, err := h.meter.NewInt64ValueRecorder(ServerLatency)
-	handleErr(err)
+	h.errorHandler.handleErr(err)
 
 	h.counters[RequestContentLength] = requestBytesCounter
 	h.counters[ResponseContentLength] = responseBytesCounter
+	h.valueRecorders[ResponseBodySize] = responseBodySizeMeasure
 	h.valueRecorders[ServerLatency] = serverLatencyMeasure
+
+	if h.requestBodySize {
+		requestBodySizeMeasure, err := h.meter.NewInt64ValueRecorder(RequestBodySize, metric.WithUnit(unit.Bytes))
+		h.errorHandler.handleErr(err)
+		h.valueRecorders[RequestBodySize] = requestBodySizeMeasure
+	}
+
+	if h.readDuration {
+		requestReadDurationMeasure, err := h.meter.NewInt64ValueRecorder(ServerRequestReadDuration)
+		h.errorHandler.handleErr(err)
+		h.valueRecorders[ServerRequestReadDuration] = requestReadDurationMeasure
+	}
+
+	if h.handlerDuration {
+		serverHandlerLatencyMeasure, err := h.meter.NewInt64ValueRecorder(ServerHandlerLatency)
+		h.errorHandler.handleErr(err)
+		h.valueRecorders[ServerHandlerLatency] = serverHandlerLatencyMeasure
+	}
+
+	if h.countRejections {
+		rejectedCounter, err := h.meter.NewInt64Counter(ServerRejected)
+		h.errorHandler.handleErr(err)
+		h.counters[ServerRejected] = rejectedCounter
+	}
+
+	if h.verifyPropagation {
+		missingParentCounter, err := h.meter.NewInt64Counter(ServerMissingParent)
+		h.errorHandler.handleErr(err)
+		h.counters[ServerMissingParent] = missingParentCounter
+	}
+
+	if h.stallThreshold > 0 {
+		stallCounter, err := h.meter.NewInt64Counter(ServerRequestStalls)
+		h.errorHandler.handleErr(err)
+		h.counters[ServerRequestStalls] = stallCounter
+	}
+
+	if h.writeDuration {
+		writeDurationMeasure, err := h.meter.NewInt64ValueRecorder(ServerResponseWriteDuration)
+		h.errorHandler.handleErr(err)
+		h.valueRecorders[ServerResponseWriteDuration] = writeDurationMeasure
+	}
+
+	if h.spanLimiter != nil {
+		limiter := h.spanLimiter
+		_, err = h.meter.NewInt64SumObserver(
+			ServerSpansDropped,
+			func(_ context.Context, result metric.Int64ObserverResult) {
+				result.Observe(limiter.droppedCount(), semconv.HTTPServerNameKey.String(h.operation))
+			},
+			metric.WithDescription("counts the inbound HTTP requests served without a span as the span rate limit was reached"),
+		)
+		h.errorHandler.handleErr(err)
+	}
+
+	if h.activeRequests != nil {
+		active, server := h.activeRequests, semconv.HTTPServerNameKey.String(h.operation)
+		_, err = h.meter.NewInt64ValueObserver(
+			ServerActiveRequests,
+			func(_ context.Context, result metric.Int64ObserverResult) {
+				result.Observe(atomic.LoadInt64(active), server)
+			},
+			metric.WithDescription("measures the number of inbound HTTP requests being served"),
+		)
+		h.errorHandler.handleErr(err)
+	}
 }
 
+// requestStallEvent is the name of the span event added when a read from a
+// request body blocks longer than the threshold configured with
+// WithRequestStallThreshold.
+const requestStallEvent = "http.server.request.stall"
+
 // ServeHTTP serves HTTP requests (http.Handler)
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requestInfoFromContext(r.Context()); ok {
+		// The request is already instrumented by an outer Handler.
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
 	requestStartTime := time.Now()
 	for _, f := range h.filters {
 		if !f(r) {
@@ -121,14 +318,73 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// attrReq is the request the attributes are recorded for, with its
+	// method overridden if WithMethodOverrideHeader is used.
+	attrReq := r
+	method, overridden := EffectiveMethod(r, h.methodOverride)
+	if overridden {
+		attrReq = withMethod(r, method)
+	}
+
+	attrs := ServerRequestAttributes(h.operation, "", attrReq)
+	if h.recordQuery {
+		attrs = redactURLAttributes(attrs, h.queryRedactor)
+	}
 	opts := append([]trace.SpanOption{
-		trace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", r)...),
-		trace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(r)...),
-		trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(h.operation, "", r)...),
+		trace.WithAttributes(attrs...),
 	}, h.spanStartOptions...) // start with the configured options
+	if overridden {
+		opts = append(opts, trace.WithAttributes(RequestMethodOriginalKey.String(r.Method)))
+	}
+	// scheme replaces the http.scheme attribute and label of the request
+	// if WithTrustForwardedHeaders is used and a proxy reported it.
+	var scheme string
+	if h.trustForwarded {
+		if s, ok := ForwardedScheme(r); ok {
+			scheme = s
+			opts = append(opts, trace.WithAttributes(semconv.HTTPSchemeKey.String(scheme)))
+		}
+	}
+	if h.authScheme {
+		if scheme, ok := AuthScheme(r); ok {
+			opts = append(opts, trace.WithAttributes(RequestAuthSchemeKey.String(scheme)))
+		}
+	}
+	var apiVersion string
+	if h.apiVersion != nil {
+		if apiVersion = h.apiVersion(r); apiVersion != "" {
+			opts = append(opts, trace.WithAttributes(APIVersionKey.String(apiVersion)))
+		}
+	}
+	if h.clientIP != nil {
+		if ip, ok := h.clientIP.clientIP(r); ok {
+			opts = append(opts, trace.WithAttributes(semconv.HTTPClientIPKey.String(ip)))
+		}
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		opts = append(opts, trace.WithAttributes(RequestContentTypeKey.String(h.contentTypeClass(ct))))
+	}
+	if h.samplingHint != nil {
+		opts = append(opts, samplingOptions(h.samplingHint(r))...)
+	}
+	if h.headersSize {
+		opts = append(opts, trace.WithAttributes(RequestHeadersSizeKey.Int64(headersSize(r.Header))))
+	}
+	if h.contextAttributes != nil {
+		if attrs := h.contextAttributes(r.Context()); len(attrs) > 0 {
+			opts = append(opts, trace.WithAttributes(attrs...))
+		}
+	}
+	if id, ok := TransactionIDFromContext(r.Context()); ok {
+		opts = append(opts, trace.WithAttributes(TransactionIDKey.String(id)))
+	}
+	if h.recordQuery && r.URL.RawQuery != "" {
+		opts = append(opts, trace.WithAttributes(URLQueryKey.String(redactQuery(r.URL.RawQuery, h.queryRedactor))))
+	}
 
 	ctx := h.propagators.Extract(r.Context(), r.Header)
-	ctx, span := h.tracer.Start(ctx, h.spanNameFormatter(h.operation, r), opts...)
+	missingParent := h.verifyPropagation && !trace.RemoteSpanContextFromContext(ctx).IsValid()
+	ctx, span := h.spanLimiter.start(ctx, h.tracer, h.spanNameFormatter(h.operation, attrReq), opts...)
 	defer span.End()
 
 	readRecordFunc := func(int64) {}
@@ -137,8 +393,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			span.AddEvent("read", trace.WithAttributes(ReadBytesKey.Int64(n)))
 		}
 	}
-	bw := bodyWrapper{ReadCloser: r.Body, record: readRecordFunc}
-	r.Body = &bw
+	bw := bodyWrapper{ReadCloser: r.Body, record: readRecordFunc, tee: bodyTee(ctx, h.bodyTee, RequestBodyDirection)}
+	if h.stallThreshold > 0 {
+		bw.stallThreshold = h.stallThreshold
+		bw.stalled = func(d time.Duration) {
+			span.AddEvent(requestStallEvent, trace.WithAttributes(RequestStallDurationKey.Int64(d.Microseconds())))
+		}
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		// Leave empty bodies as they are, so handlers can still compare
+		// them to http.NoBody.
+		r.Body = bw.wrap()
+	}
 
 	writeRecordFunc := func(int64) {}
 	if h.writeEvent {
@@ -148,12 +414,14 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rww := &respWriterWrapper{ResponseWriter: w, record: writeRecordFunc, ctx: ctx, props: h.propagators}
+	rww.tee = bodyTee(ctx, h.bodyTee, ResponseBodyDirection)
+	rww.timeWrites = h.writeDuration
 
 	// Wrap w to use our ResponseWriter methods while also exposing
 	// other interfaces that w may implement (http.CloseNotifier,
 	// http.Flusher, http.Hijacker, http.Pusher, io.ReaderFrom).
 
-	w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+	hooks := httpsnoop.Hooks{
 		Header: func(httpsnoop.HeaderFunc) httpsnoop.HeaderFunc {
 			return rww.Header
 		},
@@ -163,25 +431,158 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		WriteHeader: func(httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
 			return rww.WriteHeader
 		},
-	})
+	}
+	if h.handlerDuration {
+		// The response of a hijacked connection must not be flushed.
+		hooks.Hijack = func(next httpsnoop.HijackFunc) httpsnoop.HijackFunc {
+			return func() (net.Conn, *bufio.ReadWriter, error) {
+				rww.hijacked = true
+				return next()
+			}
+		}
+	}
+	w = httpsnoop.Wrap(w, hooks)
 
 	labeler := &Labeler{}
-	ctx = injectLabeler(ctx, labeler)
+	ctx = ContextWithLabeler(ctx, labeler)
+	ctx = ContextWithObservations(ctx)
+	info := &requestInfo{trimTrailingSlash: h.trimTrailingSlash, countRejections: h.countRejections}
+	ctx = injectRequestInfo(ctx, info)
+
+	if h.activeRequests != nil {
+		atomic.AddInt64(h.activeRequests, 1)
+		defer atomic.AddInt64(h.activeRequests, -1)
+	}
+	handlerStartTime := time.Now()
+	served := r.WithContext(ctx)
+	h.handler.ServeHTTP(w, served)
+	handlerElapsedTime := time.Since(handlerStartTime).Microseconds()
+	if f, ok := w.(http.Flusher); ok && h.handlerDuration && !rww.hijacked {
+		// Write out the response still buffered by the server, so that
+		// ServerLatency includes the time a slow client takes to receive it.
+		f.Flush()
+	}
 
-	h.handler.ServeHTTP(w, r.WithContext(ctx))
+	if h.serveMuxPattern || h.routeIDPatterns != nil {
+		h.nameAfterRoute(span, info, served)
+	}
 
 	setAfterServeAttributes(span, bw.read, rww.written, rww.statusCode, bw.err, rww.err)
+	// The read duration is only known if the handler read the whole body.
+	readElapsedTime := int64(-1)
+	if h.readDuration && !bw.eof.IsZero() {
+		readElapsedTime = bw.eof.Sub(handlerStartTime).Microseconds()
+		span.SetAttributes(RequestReadDurationKey.Int64(readElapsedTime))
+	}
+	// The handler may return without writing, leaving it to the server.
+	writeElapsedTime := int64(-1)
+	if h.writeDuration && rww.wroteHeader {
+		writeElapsedTime = rww.writeDuration.Microseconds()
+		span.SetAttributes(ResponseWriteDurationKey.Int64(writeElapsedTime))
+	}
+	observations := ObservationsFromContext(ctx)
+	span.SetAttributes(observations...)
+	if h.spanEndHook != nil {
+		h.spanEndHook(ctx, span, r)
+	}
 
 	// Add request metrics
 
-	labels := append(labeler.Get(), semconv.HTTPServerMetricAttributesFromHTTPRequest(h.operation, r)...)
+	labels := append(labeler.Get(), ServerMetricLabels(h.operation, r, h.methodOverride, scheme)...)
+	if h.apiVersionLabels != nil && apiVersion != "" {
+		if !h.apiVersionLabels[apiVersion] {
+			apiVersion = "_OTHER"
+		}
+		labels = append(labels, APIVersionKey.String(apiVersion))
+	}
+	if h.sortedLabels {
+		sortLabels(labels)
+	}
 
+	recordObservations(ctx, h.obsRecorders, observations, labels)
 	h.counters[RequestContentLength].Add(ctx, bw.read, labels...)
+	if h.requestBodySize {
+		h.valueRecorders[RequestBodySize].Record(ctx, bw.read, labels...)
+	}
 	h.counters[ResponseContentLength].Add(ctx, rww.written, labels...)
+	h.valueRecorders[ResponseBodySize].Record(ctx, rww.written, labels...)
 
 	elapsedTime := time.Since(requestStartTime).Microseconds()
 
 	h.valueRecorders[ServerLatency].Record(ctx, elapsedTime, labels...)
+	if h.handlerDuration {
+		h.valueRecorders[ServerHandlerLatency].Record(ctx, handlerElapsedTime, labels...)
+	}
+	if readElapsedTime >= 0 {
+		h.valueRecorders[ServerRequestReadDuration].Record(ctx, readElapsedTime, labels...)
+	}
+	if writeElapsedTime >= 0 {
+		h.valueRecorders[ServerResponseWriteDuration].Record(ctx, writeElapsedTime, labels...)
+	}
+	if bw.stalls > 0 {
+		h.counters[ServerRequestStalls].Add(ctx, bw.stalls, labels...)
+	}
+
+	if rejected, route := info.rejection(); rejected {
+		h.counters[ServerRejected].Add(ctx, 1, h.withRoute(labels, route)...)
+	}
+	if missingParent {
+		h.counters[ServerMissingParent].Add(ctx, 1, h.withRoute(labels, info.getRoute())...)
+	}
+}
+
+// nameAfterRoute names span after the route of r, which it records as the
+// http.route attribute and shares through info if no route was set with
+// WithRouteTag. The route is the pattern of the http.ServeMux route of r if
+// WithServeMuxPattern is used, else, if WithAutoRouteNormalization is used,
+// the route set with WithRouteTag or the URL path of r with its IDs
+// replaced. Without a route, span is named after the URL path of r.
+func (h *Handler) nameAfterRoute(span trace.Span, info *requestInfo, r *http.Request) {
+	var route string
+	if h.serveMuxPattern {
+		route = patternRoute(requestPattern(r))
+	}
+	if route == "" && h.routeIDPatterns != nil {
+		if route = info.getRoute(); route == "" {
+			route = replaceRouteIDs(r.URL.Path, h.routeIDPatterns)
+		}
+	}
+	if route == "" {
+		span.SetName(info.normalizeRoute(r.URL.Path))
+		return
+	}
+	route = info.normalizeRoute(route)
+	span.SetName(route)
+	span.SetAttributes(semconv.HTTPRouteKey.String(route))
+	if info.getRoute() == "" {
+		info.setRoute(route)
+	}
+}
+
+// withRoute returns labels with the HTTPRouteKey label for route added, if
+// route is known, sorted if WithSortedLabels is used. labels is not
+// modified.
+func (h *Handler) withRoute(labels []label.KeyValue, route string) []label.KeyValue {
+	if route == "" {
+		return labels
+	}
+	labels = append(labels[:len(labels):len(labels)], semconv.HTTPRouteKey.String(route))
+	if h.sortedLabels {
+		sortLabels(labels)
+	}
+	return labels
+}
+
+// setLabel replaces the label of labels with the key of kv by kv, or
+// appends kv if there is none.
+func setLabel(labels []label.KeyValue, kv label.KeyValue) []label.KeyValue {
+	for i := range labels {
+		if labels[i].Key == kv.Key {
+			labels[i] = kv
+			return labels
+		}
+	}
+	return append(labels, kv)
 }
 
 func setAfterServeAttributes(span trace.Span, read, wrote int64, statusCode int, rerr, werr error) {
@@ -208,11 +609,34 @@ func setAfterServeAttributes(span trace.Span, read, wrote int64, statusCode int,
 	span.SetAttributes(labels...)
 }
 
+// patternRoute returns the path of an http.ServeMux pattern, leaving out
+// its method and host, if any.
+func patternRoute(pattern string) string {
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		return pattern[i:]
+	}
+	return ""
+}
+
+// trimTrailingSlash returns route without its trailing slash, unless it is
+// the root route "/".
+func trimTrailingSlash(route string) string {
+	if len(route) > 1 && strings.HasSuffix(route, "/") {
+		return route[:len(route)-1]
+	}
+	return route
+}
+
 // WithRouteTag annotates a span with the provided route name using the
 // RouteKey Tag.
 func WithRouteTag(route string, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		span := trace.SpanFromContext(r.Context())
+		route := route
+		if info, ok := requestInfoFromContext(r.Context()); ok {
+			route = info.normalizeRoute(route)
+			info.setRoute(route)
+		}
+		span := SpanFromContext(r.Context())
 		span.SetAttributes(semconv.HTTPRouteKey.String(route))
 		h.ServeHTTP(w, r)
 	})
@@ -14,18 +14,24 @@
 package otelhttp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/oteltest"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv"
@@ -83,6 +89,17 @@ func TestHandlerBasics(t *testing.T) {
 
 	assertMetricLabels(t, labelsToVerify, meterimpl.MeasurementBatches)
 
+	var names []string
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		names = append(names, m.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		RequestContentLength,
+		ResponseContentLength,
+		ResponseBodySize,
+		ServerLatency,
+	}, names)
+
 	if got, expected := rr.Result().StatusCode, http.StatusOK; got != expected {
 		t.Fatalf("got %d, expected %d", got, expected)
 	}
@@ -176,3 +193,551 @@ func TestResponseWriterOptionalInterfaces(t *testing.T) {
 		t.Fatal("http.Flusher interface not exposed")
 	}
 }
+
+func TestHandlerRequestBodySize(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body io.Reader
+		read bool
+		want int64
+	}{
+		{name: "read", body: ioutil.NopCloser(strings.NewReader("hello world")), read: true, want: 11},
+		{name: "unread", body: ioutil.NopCloser(strings.NewReader("hello world")), want: 0},
+		{name: "empty", want: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.read {
+					_, err := ioutil.ReadAll(r.Body)
+					assert.NoError(t, err)
+				}
+			}), "test_handler", WithMeterProvider(meterProvider), WithRequestBodySize(true))
+
+			// The body is wrapped so that its length is unknown, like for
+			// chunked requests.
+			r := httptest.NewRequest(http.MethodPost, "/", tc.body)
+			require.Equal(t, tc.body == nil, r.ContentLength == 0)
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			var sizes []int64
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == RequestBodySize {
+					sizes = append(sizes, m.Number.AsInt64())
+				}
+			}
+			assert.Equal(t, []int64{tc.want}, sizes)
+		})
+	}
+}
+
+func TestHandlerRequestBodySizeDisabled(t *testing.T) {
+	names := instrumentNames(func(mp metric.MeterProvider) {
+		NewHandler(http.NotFoundHandler(), "test_handler", WithMeterProvider(mp))
+	})
+	assert.NotContains(t, names, RequestBodySize)
+}
+
+func TestHandlerResponseBodySize(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello ")
+		_, _ = io.WriteString(w, "world")
+	}), "test_handler", WithMeterProvider(meterProvider))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var sizes []int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == ResponseBodySize {
+			sizes = append(sizes, m.Number.AsInt64())
+		}
+	}
+	assert.Equal(t, []int64{11}, sizes)
+}
+
+// slowReader is a reader that waits before reporting the end of its data,
+// like the body of a slow upload.
+type slowReader struct {
+	io.Reader
+	delay time.Duration
+}
+
+func (r slowReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		time.Sleep(r.delay)
+	}
+	return n, err
+}
+
+func TestHandlerRequestReadDuration(t *testing.T) {
+	for _, read := range []bool{false, true} {
+		spanRecorder := new(oteltest.StandardSpanRecorder)
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if read {
+				_, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+			}
+		}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder))),
+			WithMeterProvider(meterProvider),
+			WithRequestReadDuration(true),
+		)
+
+		delay := 10 * time.Millisecond
+		body := slowReader{Reader: strings.NewReader("hello world"), delay: delay}
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", body))
+
+		spans := spanRecorder.Completed()
+		require.Len(t, spans, 1)
+		var durations []int64
+		for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+			if m.Name == ServerRequestReadDuration {
+				durations = append(durations, m.Number.AsInt64())
+			}
+		}
+		if !read {
+			assert.NotContains(t, spans[0].Attributes(), RequestReadDurationKey)
+			assert.Empty(t, durations)
+			continue
+		}
+		attr, ok := spans[0].Attributes()[RequestReadDurationKey]
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, attr.AsInt64(), delay.Microseconds())
+		assert.Equal(t, []int64{attr.AsInt64()}, durations)
+	}
+}
+
+func TestHandlerRequestReadDurationDisabled(t *testing.T) {
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	names := instrumentNames(func(mp metric.MeterProvider) {
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+		}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder))),
+			WithMeterProvider(mp),
+		)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello world")))
+	})
+
+	assert.NotContains(t, names, ServerRequestReadDuration)
+	spans := spanRecorder.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), RequestReadDurationKey)
+}
+
+func TestRequestBodyOptionalInterfaces(t *testing.T) {
+	var isNoBody, isWriterTo bool
+	var read int64
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isNoBody = r.Body == http.NoBody
+		_, isWriterTo = r.Body.(io.WriterTo)
+		if isWriterTo {
+			var err error
+			read, err = io.Copy(ioutil.Discard, r.Body)
+			assert.NoError(t, err)
+		}
+	}), "test_handler", WithTracerProvider(oteltest.NewTracerProvider()))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, isNoBody, "http.NoBody not preserved")
+
+	// The body returned by io.NopCloser implements io.WriterTo if the
+	// reader it wraps does.
+	body := ioutil.NopCloser(strings.NewReader("hello world"))
+	if _, ok := body.(io.WriterTo); !ok {
+		t.Skip("io.NopCloser does not implement io.WriterTo")
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", body))
+	assert.True(t, isWriterTo, "io.WriterTo interface not exposed")
+	assert.Equal(t, int64(11), read)
+}
+
+func TestHandlerAppliedTwice(t *testing.T) {
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	opts := []Option{
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder))),
+		WithMeterProvider(meterProvider),
+	}
+
+	var served int
+	inner := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.WriteHeader(http.StatusTeapot)
+	}), "inner", opts...)
+	h := NewHandler(inner, "outer", opts...)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, 1, served)
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+
+	spans := spanRecorder.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "outer", spans[0].Name())
+	assert.Equal(t, 1, countMeasurements(meterimpl, ServerLatency))
+}
+
+func TestHandlerSpanEndHook(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(
+		oteltest.WithSpanRecorder(spanRecorder),
+	)
+
+	var calls int
+	h := NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}), "test_handler",
+		WithTracerProvider(provider),
+		WithServerSpanEndHook(func(ctx context.Context, span trace.Span, r *http.Request) {
+			calls++
+			mockSpan, ok := span.(*oteltest.Span)
+			if assert.True(t, ok) {
+				assert.False(t, mockSpan.Ended())
+				assert.Equal(t, label.IntValue(http.StatusNotFound), mockSpan.Attributes()[semconv.HTTPStatusCodeKey])
+			}
+		}),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(rr, r)
+
+	assert.Equal(t, 1, calls)
+	assert.Len(t, spanRecorder.Completed(), 1)
+}
+
+// failingMeterImpl fails to create the synchronous instruments whose name is
+// in fail.
+type failingMeterImpl struct {
+	*oteltest.MeterImpl
+	fail map[string]bool
+}
+
+func (m failingMeterImpl) NewSyncInstrument(d metric.Descriptor) (metric.SyncImpl, error) {
+	if m.fail[d.Name()] {
+		return nil, errors.New("failed to create instrument")
+	}
+	return m.MeterImpl.NewSyncInstrument(d)
+}
+
+type meterProviderFunc func(string, ...metric.MeterOption) metric.Meter
+
+func (f meterProviderFunc) Meter(name string, opts ...metric.MeterOption) metric.Meter {
+	return f(name, opts...)
+}
+
+// namingMeterImpl appends the names of the synchronous instruments it
+// creates to names.
+type namingMeterImpl struct {
+	*oteltest.MeterImpl
+	names *[]string
+}
+
+func (m namingMeterImpl) NewSyncInstrument(d metric.Descriptor) (metric.SyncImpl, error) {
+	*m.names = append(*m.names, d.Name())
+	return m.MeterImpl.NewSyncInstrument(d)
+}
+
+// instrumentNames returns the names of the synchronous instruments created
+// from the MeterProvider passed to create.
+func instrumentNames(create func(metric.MeterProvider)) []string {
+	var names []string
+	meterimpl, _ := oteltest.NewMeterProvider()
+	impl := namingMeterImpl{MeterImpl: meterimpl, names: &names}
+	create(meterProviderFunc(func(name string, opts ...metric.MeterOption) metric.Meter {
+		return metric.WrapMeterImpl(impl, name, opts...)
+	}))
+	return names
+}
+
+func TestInstrumentationErrors(t *testing.T) {
+	meterimpl, _ := oteltest.NewMeterProvider()
+	impl := failingMeterImpl{MeterImpl: meterimpl, fail: map[string]bool{ServerLatency: true}}
+	meterProvider := meterProviderFunc(func(name string, opts ...metric.MeterOption) metric.Meter {
+		return metric.WrapMeterImpl(impl, name, opts...)
+	})
+
+	h := NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		"test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithMeterProvider(meterProvider),
+	)
+	r, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	var count int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == instrumentationErrors {
+			count += m.Number.AsInt64()
+		}
+	}
+	assert.Equal(t, int64(1), count)
+}
+
+func TestErrorHandlerBrokenCounter(t *testing.T) {
+	// The zero value has no instrument to count with.
+	assert.NotPanics(t, func() {
+		errorHandler{}.handleErr(errors.New("test error"))
+	})
+}
+
+func TestPropagationVerification(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	provider := oteltest.NewTracerProvider()
+	propagator := propagation.TraceContext{}
+
+	var mux http.ServeMux
+	mux.Handle("/users/", WithRouteTag("/users/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	h := NewHandler(&mux, "test_handler",
+		WithTracerProvider(provider),
+		WithMeterProvider(meterProvider),
+		WithPropagators(propagator),
+		WithPropagationVerification(true),
+	)
+
+	for _, propagated := range []bool{true, false} {
+		r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		if propagated {
+			ctx, span := provider.Tracer("caller").Start(context.Background(), "call")
+			propagator.Inject(ctx, r.Header)
+			span.End()
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	var missing []oteltest.Measured
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == ServerMissingParent {
+			missing = append(missing, m)
+		}
+	}
+	require.Len(t, missing, 1)
+	assert.Equal(t, int64(1), missing[0].Number.AsInt64())
+	assert.Equal(t, label.StringValue("/users/:id"), missing[0].Labels[semconv.HTTPRouteKey])
+}
+
+func TestPropagationVerificationDisabled(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	h := NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		"test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithMeterProvider(meterProvider),
+	)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		assert.NotEqual(t, ServerMissingParent, m.Name)
+	}
+	names := instrumentNames(func(mp metric.MeterProvider) {
+		NewHandler(http.NotFoundHandler(), "test_handler", WithMeterProvider(mp))
+	})
+	assert.NotContains(t, names, ServerMissingParent)
+}
+
+func TestActiveRequestsGauge(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+		observe := func() []int64 {
+			meterimpl.MeasurementBatches = nil
+			meterimpl.RunAsyncInstruments()
+			var values []int64
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == ServerActiveRequests {
+					assert.Equal(t, label.StringValue("test_handler"), m.Labels[semconv.HTTPServerNameKey])
+					values = append(values, m.Number.AsInt64())
+				}
+			}
+			return values
+		}
+
+		var during []int64
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			during = observe()
+		}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider()),
+			WithMeterProvider(meterProvider),
+			WithActiveRequestsGauge(enabled),
+		)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if !enabled {
+			assert.Empty(t, during)
+			assert.Empty(t, observe())
+			continue
+		}
+		assert.Equal(t, []int64{1}, during)
+		assert.Equal(t, []int64{0}, observe())
+	}
+}
+
+func TestHandlerRequestStallThreshold(t *testing.T) {
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+	}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder))),
+		WithMeterProvider(meterProvider),
+		WithRequestStallThreshold(5*time.Millisecond),
+	)
+
+	delay := 10 * time.Millisecond
+	body := slowReader{Reader: strings.NewReader("hello world"), delay: delay}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", body))
+
+	spans := spanRecorder.Completed()
+	require.Len(t, spans, 1)
+	var stalls []oteltest.Event
+	for _, e := range spans[0].Events() {
+		if e.Name == "http.server.request.stall" {
+			stalls = append(stalls, e)
+		}
+	}
+	require.Len(t, stalls, 1)
+	assert.GreaterOrEqual(t, stalls[0].Attributes[RequestStallDurationKey].AsInt64(), delay.Microseconds())
+	var counted []int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == ServerRequestStalls {
+			counted = append(counted, m.Number.AsInt64())
+		}
+	}
+	assert.Equal(t, []int64{1}, counted)
+}
+
+type slowResponseWriter struct {
+	http.ResponseWriter
+	delay time.Duration
+}
+
+func (w slowResponseWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.ResponseWriter.Write(p)
+}
+
+func TestHandlerResponseWriteDuration(t *testing.T) {
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello ")
+		_, _ = io.WriteString(w, "world")
+	}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder))),
+		WithMeterProvider(meterProvider),
+		WithResponseWriteDuration(true),
+	)
+
+	delay := 5 * time.Millisecond
+	w := slowResponseWriter{ResponseWriter: httptest.NewRecorder(), delay: delay}
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := spanRecorder.Completed()
+	require.Len(t, spans, 1)
+	// The duration is summed over both writes.
+	attr, ok := spans[0].Attributes()[ResponseWriteDurationKey]
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, attr.AsInt64(), 2*delay.Microseconds())
+	var recorded []int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == ServerResponseWriteDuration {
+			recorded = append(recorded, m.Number.AsInt64())
+		}
+	}
+	assert.Equal(t, []int64{attr.AsInt64()}, recorded)
+}
+
+func TestHandlerResponseWriteDurationDisabled(t *testing.T) {
+	spanRecorder := new(oteltest.StandardSpanRecorder)
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello world")
+	}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(spanRecorder))),
+	)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := spanRecorder.Completed()
+	require.Len(t, spans, 1)
+	_, ok := spans[0].Attributes()[ResponseWriteDurationKey]
+	assert.False(t, ok)
+}
+
+// slowListener accepts connections whose writes take delay, like those to a
+// client slow to read its responses.
+type slowListener struct {
+	net.Listener
+	delay time.Duration
+}
+
+func (l slowListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return slowConn{Conn: c, delay: l.delay}, nil
+}
+
+type slowConn struct {
+	net.Conn
+	delay time.Duration
+}
+
+func (c slowConn) Write(p []byte) (int, error) {
+	time.Sleep(c.delay)
+	return c.Conn.Write(p)
+}
+
+func TestHandlerDuration(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	for _, enabled := range []bool{false, true} {
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "hello world")
+		}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider()),
+			WithMeterProvider(meterProvider),
+			WithHandlerDuration(enabled),
+		)
+		ts := httptest.NewUnstartedServer(h)
+		ts.Listener = slowListener{Listener: ts.Listener, delay: delay}
+		ts.Start()
+
+		res, err := ts.Client().Get(ts.URL)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, "hello world", string(body))
+		// Close waits for the handler to record its metrics.
+		ts.Close()
+
+		durations := map[string]int64{}
+		for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+			if m.Name == ServerLatency || m.Name == ServerHandlerLatency {
+				durations[m.Name] = m.Number.AsInt64()
+			}
+		}
+		if !enabled {
+			// The server writes the response out after the Handler returns.
+			assert.NotContains(t, durations, ServerHandlerLatency)
+			assert.Less(t, durations[ServerLatency], delay.Microseconds())
+			continue
+		}
+		require.Contains(t, durations, ServerHandlerLatency)
+		assert.Less(t, durations[ServerHandlerLatency], delay.Microseconds())
+		assert.GreaterOrEqual(t, durations[ServerLatency], delay.Microseconds())
+	}
+}
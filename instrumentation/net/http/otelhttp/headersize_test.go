@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestHeadersSize(t *testing.T) {
+	assert.Equal(t, int64(0), headersSize(nil))
+	assert.Equal(t, int64(0), headersSize(http.Header{}))
+	assert.Equal(t, int64(len("Cookie")+len("a=1")+len("Cookie")+len("b=22")+len("Accept")+len("*/*")), headersSize(http.Header{
+		"Cookie": {"a=1", "b=22"},
+		"Accept": {"*/*"},
+	}))
+}
+
+func TestHandlerRequestHeadersSize(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithRequestHeadersSize(enabled),
+		)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer token")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		if enabled {
+			assert.Equal(t, label.Int64Value(int64(len("Authorization")+len("Bearer token"))), spans[0].Attributes()[RequestHeadersSizeKey])
+		} else {
+			assert.NotContains(t, spans[0].Attributes(), RequestHeadersSizeKey)
+		}
+	}
+}
+
+func TestTransportRequestHeadersSize(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	var sent http.Header
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		sent = r.Header.Clone()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithPropagators(propagation.TraceContext{}),
+		WithRequestHeadersSize(true),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	r.Header.Set("Cookie", "session=abc")
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	require.NotEmpty(t, sent.Get("Traceparent"), "the injected trace context is measured")
+	assert.Equal(t, label.Int64Value(headersSize(sent)), spans[0].Attributes()[RequestHeadersSizeKey])
+}
@@ -48,8 +48,13 @@ type labelerContextKeyType int
 
 const lablelerContextKey labelerContextKeyType = 0
 
-func injectLabeler(ctx context.Context, l *Labeler) context.Context {
-	return context.WithValue(ctx, lablelerContextKey, l)
+// ContextWithLabeler returns a copy of parent holding l, which
+// LabelerFromContext then returns. The Handler stores the Labeler of the
+// metrics of each request it serves this way. Middleware serving requests
+// without a Handler, or tests of handlers, can use it to provide a Labeler
+// whose labels they read back with Labeler.Get.
+func ContextWithLabeler(parent context.Context, l *Labeler) context.Context {
+	return context.WithValue(parent, lablelerContextKey, l)
 }
 
 // LabelerFromContext retrieves a Labeler instance from the provided context if
@@ -0,0 +1,14 @@
+module go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/latencyfilter
+
+go 1.14
+
+replace (
+	go.opentelemetry.io/contrib => ../../../../../
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp => ../
+)
+
+require (
+	github.com/stretchr/testify v1.6.1
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.14.0
+	go.opentelemetry.io/otel/sdk v0.14.0
+)
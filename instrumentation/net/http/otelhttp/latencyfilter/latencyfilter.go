@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package latencyfilter provides a SpanProcessor for the OpenTelemetry trace
+// SDK exporting only the spans of slow outbound requests, those otelhttp
+// marks as exceeding the threshold of otelhttp.WithLatencyThresholdTracing.
+// Whether a request is slow is only known once it completes, after the
+// sampling decision, so the spans are filtered when they end, where the SDK
+// is set up:
+//
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(
+//		latencyfilter.NewSpanProcessor(sdktrace.NewBatchSpanProcessor(exporter)),
+//	))
+//	client := http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport,
+//		otelhttp.WithTracerProvider(tp),
+//		otelhttp.WithLatencyThresholdTracing(500*time.Millisecond),
+//	)}
+package latencyfilter // import "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/latencyfilter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type spanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+// NewSpanProcessor returns a SpanProcessor passing the spans it is given to
+// next, except, when they end, the spans marked with the
+// otelhttp.LatencyThresholdExceededKey attribute set to false. Spans without
+// the attribute, like those of other instrumentation, are all passed.
+func NewSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	return &spanProcessor{next: next}
+}
+
+func (p *spanProcessor) OnStart(parent context.Context, sd *export.SpanData) {
+	p.next.OnStart(parent, sd)
+}
+
+func (p *spanProcessor) OnEnd(sd *export.SpanData) {
+	for _, kv := range sd.Attributes {
+		if kv.Key == otelhttp.LatencyThresholdExceededKey && !kv.Value.AsBool() {
+			return
+		}
+	}
+	p.next.OnEnd(sd)
+}
+
+func (p *spanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *spanProcessor) ForceFlush() {
+	p.next.ForceFlush()
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latencyfilter_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/latencyfilter"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// recorder records the names of the spans that ended.
+type recorder struct {
+	mu    sync.Mutex
+	ended []string
+}
+
+func (r *recorder) OnStart(context.Context, *export.SpanData) {}
+
+func (r *recorder) OnEnd(sd *export.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended = append(r.ended, sd.Name)
+}
+
+func (r *recorder) Shutdown(context.Context) error { return nil }
+
+func (r *recorder) ForceFlush() {}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSpanProcessor(t *testing.T) {
+	rec := &recorder{}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSpanProcessor(latencyfilter.NewSpanProcessor(rec)),
+	)
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := otelhttp.NewTransport(base,
+		otelhttp.WithTracerProvider(tp),
+		otelhttp.WithLatencyThresholdTracing(10*time.Millisecond),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.URL.Path }),
+	)
+
+	for _, path := range []string{"/fast", "/slow", "/fast"} {
+		r, err := http.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+	// Spans of other instrumentation are passed.
+	_, span := tp.Tracer("test").Start(context.Background(), "other")
+	span.End()
+
+	assert.Equal(t, []string{"/slow", "other"}, rec.ended)
+	require.NoError(t, tp.Shutdown(context.Background()))
+}
@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type linkedSpansContextKeyType int
+
+const linkedSpansContextKey linkedSpansContextKeyType = 0
+
+// ContextWithLinkedSpan returns a copy of parent carrying sc, the span
+// context of a request the requests sent with the returned context relate
+// to, like the previous page of a paginated listing. The Transport links the
+// spans of these requests to sc when starting them, capturing relationships
+// parent-child does not. Calling it again on the returned context adds
+// another link. Invalid span contexts are ignored.
+func ContextWithLinkedSpan(parent context.Context, sc trace.SpanContext) context.Context {
+	if !sc.IsValid() {
+		return parent
+	}
+	prev := linkedSpansFromContext(parent)
+	links := make([]trace.Link, len(prev), len(prev)+1)
+	copy(links, prev)
+	return context.WithValue(parent, linkedSpansContextKey, append(links, trace.Link{SpanContext: sc}))
+}
+
+// linkedSpansFromContext returns the links stored in ctx with
+// ContextWithLinkedSpan, nil if there are none.
+func linkedSpansFromContext(ctx context.Context) []trace.Link {
+	links, _ := ctx.Value(linkedSpansContextKey).([]trace.Link)
+	return links
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextWithLinkedSpan(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	tr := NewTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), WithTracerProvider(provider))
+
+	get := func(ctx context.Context) trace.SpanContext {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/items", nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		spans := sr.Completed()
+		return spans[len(spans)-1].SpanContext()
+	}
+
+	// Each page links to the previous ones.
+	ctx := ContextWithLinkedSpan(context.Background(), trace.SpanContext{})
+	page1 := get(ctx)
+	page2Ctx := ContextWithLinkedSpan(ctx, page1)
+	page2 := get(page2Ctx)
+	get(ContextWithLinkedSpan(page2Ctx, page2))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Empty(t, spans[0].Links())
+	assert.Equal(t, map[trace.SpanContext][]label.KeyValue{page1: {}}, spans[1].Links())
+	assert.Equal(t, map[trace.SpanContext][]label.KeyValue{page1: {}, page2: {}}, spans[2].Links())
+}
@@ -18,67 +18,444 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/semconv"
 
 	"go.opentelemetry.io/otel/unit"
 
-	"go.opentelemetry.io/otel/api/metric"
 	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type instrumentedTransport struct {
-	meter                  metric.Meter
-	base                   *Transport
-	clientDurationRecorder metric.Float64ValueRecorder
+	meter                                 metric.Meter
+	base                                  *Transport
+	clientDurationRecorder                metric.Float64ValueRecorder
+	clientRequestSizeRecorder             metric.Int64ValueRecorder
+	clientRequestUncompressedSizeRecorder metric.Int64ValueRecorder
+	clientResponseSizeRecorder            metric.Int64ValueRecorder
+	clientBodyLeakedCounter               metric.Int64Counter
+	clientConnectionsNewCounter           metric.Int64Counter
+	clientConnectionsReusedCounter        metric.Int64Counter
+	clientConnectionConcurrencyRecorder   metric.Int64ValueRecorder
+	clientRootRequestsCounter             metric.Int64Counter
+	clientCacheRequestsCounter            metric.Int64Counter
+	clientRequestsSuccessCounter          metric.Int64Counter
+	clientRequestsErrorCounter            metric.Int64Counter
+	clientConditionalRequestsCounter      metric.Int64Counter
+	errorHandler                          errorHandler
+	bodyLeakDetection                     bool
+	sortedLabels                          bool
+
+	// latencySummary estimates quantiles of the durations if enabled.
+	latencySummary *latencySummary
+
+	// openBodies counts the response bodies not yet closed or read to
+	// completion if WithOpenBodiesGauge is used.
+	openBodies *int64
+
+	// observationRecorders record the observations attached to requests
+	// with ContextWithObservation, see WithObservationRecorder.
+	observationRecorders map[string]metric.Float64ValueRecorder
+
+	// cohorts are the cohorts metrics are labeled with, see WithCohorts.
+	cohorts map[string]bool
+
+	// connectionCounters is whether the connections requests are sent on
+	// are counted, see WithConnectionCounters.
+	connectionCounters bool
+	// rootRequests is whether the requests starting a trace are counted,
+	// see WithRootRequestsCounter.
+	rootRequests bool
+	// outcomeCounters is whether requests are counted by their outcome, see
+	// WithOutcomeCounters.
+	outcomeCounters bool
+
+	// globalMeterProvider is true if the instruments are created from the
+	// global MeterProvider. They are then recreated whenever it is replaced.
+	globalMeterProvider bool
+	// mu guards meterProvider, meter and the instruments while they are
+	// being recreated.
+	mu            sync.RWMutex
+	meterProvider metric.MeterProvider
 }
 
 type tracker struct {
+	// responseSize is the size of the response body, -1 if unknown, or the
+	// bytes read from it if it is wrapped, accessed atomically, so first to
+	// be 64-bit aligned
+	responseSize int64
+
 	ctx     context.Context
 	start   time.Time
 	body    io.ReadCloser
 	endOnce sync.Once
 	labels  []label.KeyValue
 
-	clientDurationRecorder metric.Float64ValueRecorder
+	// on-wire and uncompressed request body sizes, -1 if unknown
+	requestSize             int64
+	requestUncompressedSize int64
+	// requestBody counts the bytes sent for request bodies of unknown size
+	requestBody *countingBody
+
+	clientDurationRecorder                metric.Float64ValueRecorder
+	clientRequestSizeRecorder             metric.Int64ValueRecorder
+	clientRequestUncompressedSizeRecorder metric.Int64ValueRecorder
+	clientResponseSizeRecorder            metric.Int64ValueRecorder
+	clientBodyLeakedCounter               metric.Int64Counter
+	latencySummary                        *latencySummary
+
+	// outcomeCounter is clientRequestsSuccess or clientRequestsError,
+	// depending on the error class of the request, recorded with
+	// outcomeLabels, if WithOutcomeCounters is used.
+	outcomeCounter metric.Int64Counter
+	outcomeLabels  []label.KeyValue
+
+	// coalesced records whether the request was coalesced into another one
+	coalesced *coalescing
+
+	// openBodies is decremented by end if the response body was counted in
+	// it when wrapped, see WithOpenBodiesGauge.
+	openBodies *int64
+
+	// observations are recorded by end with observationRecorders, see
+	// WithObservationRecorder.
+	observations         *observations
+	observationRecorders map[string]metric.Float64ValueRecorder
+
+	// leak is set if leak detection is enabled, it has a finalizer counting
+	// the response body as leaked unless end is called.
+	leak *bodyLeak
+}
+
+// bodyLeak counts a response body as leaked when it is garbage collected.
+// It is kept apart from the tracker, which can be part of a reference cycle
+// through the response, as the finalizer of an object in a cycle is not
+// guaranteed to run.
+type bodyLeak struct {
+	ctx     context.Context
+	labels  []label.KeyValue
+	counter metric.Int64Counter
 }
 
 func (trans *instrumentedTransport) applyConfig(c *config) {
 	trans.base.applyConfig(c)
 
+	trans.meterProvider = c.MeterProvider
+	trans.globalMeterProvider = c.GlobalMeterProvider
 	trans.meter = c.Meter
+	trans.bodyLeakDetection = c.BodyLeakDetection
+	trans.connectionCounters = c.ConnectionCounters
+	trans.rootRequests = c.RootRequestsCounter
+	trans.outcomeCounters = c.OutcomeCounters
+	trans.sortedLabels = c.SortedLabels
+	trans.observationRecorders = c.ObservationRecorders
+	if len(c.Cohorts) > 0 {
+		trans.cohorts = make(map[string]bool, len(c.Cohorts))
+		for _, cohort := range c.Cohorts {
+			trans.cohorts[cohort] = true
+		}
+	}
+	if c.OpenBodiesGauge {
+		trans.openBodies = new(int64)
+	}
+	if len(c.LatencySummaryQuantiles) > 0 {
+		trans.latencySummary = newLatencySummary(c.LatencySummaryQuantiles)
+	}
 	trans.createMeasures()
 }
 
+// Rebuild recreates the instruments of the transport from the current
+// global MeterProvider. It is a no-op if the transport was configured with
+// WithMeterProvider. Rebuild is called automatically when the global
+// MeterProvider is found to have been replaced, so calling it explicitly
+// is only needed to create the instruments ahead of the next request.
+func (trans *instrumentedTransport) Rebuild() {
+	if !trans.globalMeterProvider {
+		return
+	}
+	provider := otel.GetMeterProvider()
+
+	trans.mu.Lock()
+	defer trans.mu.Unlock()
+	trans.meterProvider = provider
+	trans.meter = newMeter(provider)
+	trans.createMeasures()
+}
+
+// rebuildIfStale calls Rebuild if the global MeterProvider has been
+// replaced since the instruments were created.
+func (trans *instrumentedTransport) rebuildIfStale() {
+	if !trans.globalMeterProvider {
+		return
+	}
+	provider := otel.GetMeterProvider()
+
+	trans.mu.RLock()
+	stale := !sameMeterProvider(trans.meterProvider, provider)
+	trans.mu.RUnlock()
+	if stale {
+		trans.Rebuild()
+	}
+}
+
+// sameMeterProvider reports whether a and b are the same MeterProvider.
+// Providers of a type that cannot be compared are assumed to be the same,
+// rather than recreating the instruments on every request.
+func sameMeterProvider(a, b metric.MeterProvider) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	if ta != nil && !ta.Comparable() {
+		return true
+	}
+	return a == b
+}
+
 // RoundTrip implements http.RoundTripper, delegating to Base and recording stats for the request.
 func (trans *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	labels := semconv.HTTPClientAttributesFromHTTPRequest(req)
+	if attemptsFromContext(req.Context()) != nil {
+		// Only the logical request is measured, see WithPerAttemptSpans.
+		return trans.base.RoundTrip(req)
+	}
+
+	operation := trans.base.operationOf(req)
+	traced := trans.base.traces(req)
+	labels := ClientRequestAttributes(req)
+	if trans.base.recordQuery {
+		labels = redactURLAttributes(labels, trans.base.queryRedactor)
+	}
+	if operation != "" {
+		labels = operationLabels(labels, operation)
+	} else if template, ok := RouteTemplateFromContext(req.Context()); ok {
+		labels = routeTemplateLabels(labels, template)
+	}
+	cohort, hasCohort := cohortLabel(req.Context(), trans.cohorts)
+	if hasCohort {
+		labels = append(labels, cohort)
+	}
+
+	trans.rebuildIfStale()
 
 	ctx := req.Context()
+	trans.mu.RLock()
 	tracker := &tracker{
-		start:                  time.Now(),
-		ctx:                    ctx,
-		clientDurationRecorder: trans.clientDurationRecorder,
+		start:                                 time.Now(),
+		ctx:                                   ctx,
+		clientDurationRecorder:                trans.clientDurationRecorder,
+		clientRequestSizeRecorder:             trans.clientRequestSizeRecorder,
+		clientRequestUncompressedSizeRecorder: trans.clientRequestUncompressedSizeRecorder,
+		clientResponseSizeRecorder:            trans.clientResponseSizeRecorder,
+		clientBodyLeakedCounter:               trans.clientBodyLeakedCounter,
+		responseSize:                          -1,
+		latencySummary:                        trans.latencySummary,
+	}
+	var connections *connectionCounters
+	if trans.connectionCounters || trans.base.connConcurrency != nil {
+		connections = &connectionCounters{
+			counting:            trans.connectionCounters,
+			newConn:             trans.clientConnectionsNewCounter,
+			reusedConn:          trans.clientConnectionsReusedCounter,
+			host:                req.URL.Host,
+			concurrency:         trans.base.connConcurrency,
+			concurrencyRecorder: trans.clientConnectionConcurrencyRecorder,
+		}
+	}
+	rootRequests := trans.clientRootRequestsCounter
+	successRequests, errorRequests := trans.clientRequestsSuccessCounter, trans.clientRequestsErrorCounter
+	cacheRequests := trans.clientCacheRequestsCounter
+	conditionalRequests := trans.clientConditionalRequestsCounter
+	trans.mu.RUnlock()
+	reqCtx := ctx
+	if trans.base.coalescing {
+		reqCtx, tracker.coalesced = contextWithCoalescing(reqCtx)
+	}
+	var cache *cacheResult
+	if trans.base.cacheHits {
+		reqCtx, cache = contextWithCacheResult(reqCtx)
+	}
+	if connections != nil {
+		reqCtx = httptrace.WithClientTrace(reqCtx, connections.clientTrace(ctx))
+	}
+	if reqCtx != ctx {
+		req = req.WithContext(reqCtx)
+	}
+	if trans.rootRequests && traced && isRootRequest(ctx) {
+		rootRequests.Add(ctx, 1, hostOrOperationLabel(req, operation))
+	}
+	if len(trans.observationRecorders) > 0 {
+		tracker.observations = sentObservations(ctx)
+		tracker.observationRecorders = trans.observationRecorders
+	}
+	tracker.requestSize, tracker.requestUncompressedSize = requestBodySizes(req)
+	if tracker.requestSize < 0 {
+		// The size of a streamed body is only known once it has been sent.
+		// GetBody is kept, so retried bodies are sent but not counted.
+		tracker.requestBody = newCountingBody(req, tracker.requestUncompressedSize)
+		r := new(http.Request)
+		*r = *req
+		r.Body = tracker.requestBody.wrap()
+		req = r
 	}
 
-	resp, err := trans.base.RoundTrip(req)
-	if err != nil {
-		tracker.labels = append(labels, semconv.HTTPAttributesFromHTTPStatusCode(http.StatusInternalServerError)...)
+	resp, errorType, err := trans.base.roundTrip(req, operation, traced, tracker.requestBody)
+	if hit, ok := cache.get(); ok {
+		cacheRequests.Add(ctx, 1, CacheHitKey.Bool(hit), hostOrOperationLabel(req, operation))
+	}
+	statusCode := http.StatusInternalServerError
+	statusClass := statusClassError
+	if err == nil {
+		statusCode = resp.StatusCode
+		statusClass = statusClassOf(statusCode)
+	}
+	if err == nil && trans.base.notModified && isConditional(req) {
+		conditionalRequests.Add(ctx, 1, NotModifiedKey.Bool(statusCode == http.StatusNotModified), hostOrOperationLabel(req, operation))
+	}
+	if trans.outcomeCounters {
+		tracker.outcomeCounter = successRequests
+		tracker.outcomeLabels = []label.KeyValue{StatusClassKey.String(statusClass), hostOrOperationLabel(req, operation)}
+		if hasCohort {
+			tracker.outcomeLabels = append(tracker.outcomeLabels, cohort)
+		}
+		if errorType != "" {
+			tracker.outcomeCounter = errorRequests
+			tracker.outcomeLabels = append(tracker.outcomeLabels, ErrorTypeKey.String(errorType))
+		}
+	}
+	if errorType != "" {
+		labels = append(labels, ErrorTypeKey.String(errorType))
+	}
+	tracker.labels = append(labels, semconv.HTTPAttributesFromHTTPStatusCode(statusCode)...)
+	if trans.sortedLabels {
+		sortLabels(tracker.labels)
+		sortLabels(tracker.outcomeLabels)
+	}
+	if err != nil || trans.base.recordOnResponse {
+		if err == nil && resp.ContentLength >= 0 {
+			tracker.responseSize = resp.ContentLength
+		}
 		tracker.end()
 	} else {
-		tracker.labels = append(labels, semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
 		if resp.Body == nil {
 			tracker.end()
 		} else {
+			tracker.responseSize = 0
 			tracker.body = resp.Body
 			resp.Body = wrappedBodyIO(tracker, resp.Body)
+			if trans.openBodies != nil {
+				atomic.AddInt64(trans.openBodies, 1)
+				tracker.openBodies = trans.openBodies
+			}
+			if trans.bodyLeakDetection {
+				tracker.leak = &bodyLeak{
+					ctx:     ctx,
+					labels:  tracker.labels,
+					counter: tracker.clientBodyLeakedCounter,
+				}
+				runtime.SetFinalizer(tracker.leak, (*bodyLeak).record)
+			}
 		}
 	}
 	return resp, err
 }
 
+// connectionCounters count the connections obtained for the requests to
+// host, see clientConnectionsNew and clientConnectionsReused, if enabled.
+type connectionCounters struct {
+	counting            bool
+	newConn, reusedConn metric.Int64Counter
+	host                string
+
+	// concurrency is the in flight requests count of the Transport, if
+	// enabled, recorded with concurrencyRecorder.
+	concurrency         *connConcurrency
+	concurrencyRecorder metric.Int64ValueRecorder
+}
+
+// clientTrace returns the httptrace hooks counting the connections obtained
+// for a request sent with ctx. A request obtains several connections if the
+// base RoundTripper retries it, each of them is counted.
+func (c *connectionCounters) clientTrace(ctx context.Context) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if c.counting {
+				counter := c.newConn
+				if info.Reused {
+					counter = c.reusedConn
+				}
+				counter.Add(ctx, 1, semconv.HTTPHostKey.String(c.host))
+			}
+			if c.concurrency != nil {
+				// The Transport counts the request before this hook runs.
+				if n := c.concurrency.count(info.Conn); n > 0 {
+					c.concurrencyRecorder.Record(ctx, int64(n), semconv.HTTPHostKey.String(c.host))
+				}
+			}
+		},
+	}
+}
+
+// isRootRequest returns whether a request sent with ctx starts a trace, as
+// ctx carries neither a span nor a remote span context.
+func isRootRequest(ctx context.Context) bool {
+	return !trace.SpanContextFromContext(ctx).IsValid() && !trace.RemoteSpanContextFromContext(ctx).IsValid()
+}
+
+// hostOrOperationLabel returns the label the per host counters, like
+// clientRootRequests, use for req: the OperationKey label for operation, if
+// not empty, or the HTTPHostKey label for the host of req.
+func hostOrOperationLabel(req *http.Request, operation string) label.KeyValue {
+	if operation != "" {
+		return OperationKey.String(operation)
+	}
+	return semconv.HTTPHostKey.String(req.URL.Host)
+}
+
+// statusClassError is the StatusClassKey value of requests that failed
+// without a response.
+const statusClassError = "error"
+
+// statusClassOf returns the StatusClassKey value of a response with status
+// code, like "2xx".
+func statusClassOf(code int) string {
+	if code < 100 || code > 599 {
+		return statusClassError
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// operationLabels returns labels, the labels of a request, with those
+// identifying the server it is sent to replaced by the OperationKey label
+// for operation. labels is not modified.
+func operationLabels(labels []label.KeyValue, operation string) []label.KeyValue {
+	out := make([]label.KeyValue, 0, len(labels))
+	for _, kv := range labels {
+		if kv.Key != semconv.HTTPURLKey && kv.Key != semconv.HTTPHostKey {
+			out = append(out, kv)
+		}
+	}
+	return append(out, OperationKey.String(operation))
+}
+
+// sortLabels sorts labels by key, in place, and returns it. The sort is
+// stable, so that of several labels with the same key the last one still
+// takes precedence.
+func sortLabels(labels []label.KeyValue) []label.KeyValue {
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].Key < labels[j].Key })
+	return labels
+}
+
 // wrappedBodyIO returns a wrapped version of the original
 // Body and only implements the same combination of additional
 // interfaces as the original.
@@ -93,26 +470,192 @@ func wrappedBodyIO(wrapper io.ReadCloser, body io.ReadCloser) io.ReadCloser {
 }
 
 func (trans *instrumentedTransport) createMeasures() {
+	trans.errorHandler = newErrorHandler(trans.meter)
+
 	var err error
 	trans.clientDurationRecorder, err = trans.meter.NewFloat64ValueRecorder(
 		clientRequestDuration,
 		metric.WithDescription("measures the duration of the outbound HTTP request"),
 		metric.WithUnit(unit.Milliseconds),
 	)
-	handleErr(err)
+	trans.errorHandler.handleErr(err)
+
+	trans.clientRequestSizeRecorder, err = trans.meter.NewInt64ValueRecorder(
+		clientRequestSize,
+		metric.WithDescription("measures the on-wire size of outbound HTTP request bodies"),
+		metric.WithUnit(unit.Bytes),
+	)
+	trans.errorHandler.handleErr(err)
+
+	trans.clientRequestUncompressedSizeRecorder, err = trans.meter.NewInt64ValueRecorder(
+		clientRequestUncompressedSize,
+		metric.WithDescription("measures the uncompressed size of outbound HTTP request bodies"),
+		metric.WithUnit(unit.Bytes),
+	)
+	trans.errorHandler.handleErr(err)
+
+	trans.clientResponseSizeRecorder, err = trans.meter.NewInt64ValueRecorder(
+		clientResponseSize,
+		metric.WithDescription("measures the size of inbound HTTP response bodies"),
+		metric.WithUnit(unit.Bytes),
+	)
+	trans.errorHandler.handleErr(err)
+
+	if trans.bodyLeakDetection {
+		trans.clientBodyLeakedCounter, err = trans.meter.NewInt64Counter(
+			clientBodyLeaked,
+			metric.WithDescription("counts the outbound HTTP response bodies that were garbage collected without being closed or read to completion"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.connectionCounters {
+		trans.clientConnectionsNewCounter, err = trans.meter.NewInt64Counter(
+			clientConnectionsNew,
+			metric.WithDescription("counts the new connections outbound HTTP requests were sent on"),
+		)
+		trans.errorHandler.handleErr(err)
+
+		trans.clientConnectionsReusedCounter, err = trans.meter.NewInt64Counter(
+			clientConnectionsReused,
+			metric.WithDescription("counts the previously used connections outbound HTTP requests were sent on"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.rootRequests {
+		trans.clientRootRequestsCounter, err = trans.meter.NewInt64Counter(
+			clientRootRequests,
+			metric.WithDescription("counts the outbound HTTP requests that start a trace, as they are sent outside of any span"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.base.cacheHits {
+		trans.clientCacheRequestsCounter, err = trans.meter.NewInt64Counter(
+			clientCacheRequests,
+			metric.WithDescription("counts the outbound HTTP requests a caching RoundTripper answered from its cache, or not"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.outcomeCounters {
+		trans.clientRequestsSuccessCounter, err = trans.meter.NewInt64Counter(
+			clientRequestsSuccess,
+			metric.WithDescription("counts the outbound HTTP requests that succeeded, by status class"),
+		)
+		trans.errorHandler.handleErr(err)
+
+		trans.clientRequestsErrorCounter, err = trans.meter.NewInt64Counter(
+			clientRequestsError,
+			metric.WithDescription("counts the outbound HTTP requests that failed, by status class and error type"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.base.connConcurrency != nil {
+		trans.clientConnectionConcurrencyRecorder, err = trans.meter.NewInt64ValueRecorder(
+			clientConnectionConcurrentRequests,
+			metric.WithDescription("measures the number of requests in flight on the connection of outbound HTTP requests when it is obtained"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.base.notModified {
+		trans.clientConditionalRequestsCounter, err = trans.meter.NewInt64Counter(
+			clientConditionalRequests,
+			metric.WithDescription("counts the conditional outbound HTTP requests, by whether they were answered with a 304 Not Modified"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.base.spanLimiter != nil {
+		limiter := trans.base.spanLimiter
+		_, err = trans.meter.NewInt64SumObserver(
+			clientSpansDropped,
+			func(_ context.Context, result metric.Int64ObserverResult) {
+				result.Observe(limiter.droppedCount())
+			},
+			metric.WithDescription("counts the outbound HTTP requests sent without a span as the span rate limit was reached"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.openBodies != nil {
+		open := trans.openBodies
+		_, err = trans.meter.NewInt64ValueObserver(
+			clientOpenBodies,
+			func(_ context.Context, result metric.Int64ObserverResult) {
+				result.Observe(atomic.LoadInt64(open))
+			},
+			metric.WithDescription("measures the number of outbound HTTP response bodies not yet closed or read to completion"),
+		)
+		trans.errorHandler.handleErr(err)
+	}
+
+	if trans.latencySummary != nil {
+		_, err = trans.meter.NewFloat64ValueObserver(
+			clientRequestDurationQuantile,
+			trans.latencySummary.observe,
+			metric.WithDescription("estimates quantiles of the duration of outbound HTTP requests over each collection interval"),
+			metric.WithUnit(unit.Milliseconds),
+		)
+		trans.errorHandler.handleErr(err)
+	}
 }
 
 var _ io.ReadCloser = (*tracker)(nil)
 
 func (tracker *tracker) end() {
 	tracker.endOnce.Do(func() {
+		if tracker.leak != nil {
+			runtime.SetFinalizer(tracker.leak, nil)
+		}
+		if tracker.openBodies != nil {
+			atomic.AddInt64(tracker.openBodies, -1)
+		}
+		if tracker.outcomeLabels != nil {
+			tracker.outcomeCounter.Add(tracker.ctx, 1, tracker.outcomeLabels...)
+		}
+		if tracker.coalesced.isCoalesced() {
+			// The request shared the response of another one, which is
+			// measured, see MarkCoalesced.
+			return
+		}
+		recordObservations(tracker.ctx, tracker.observationRecorders, tracker.observations.get(), tracker.labels)
 		latencyMs := float64(time.Since(tracker.start)) / float64(time.Millisecond)
 		tracker.clientDurationRecorder.Record(tracker.ctx, latencyMs, tracker.labels...)
+		if tracker.latencySummary != nil {
+			tracker.latencySummary.record(latencyMs, tracker.labels)
+		}
+
+		requestSize, requestUncompressedSize := tracker.requestSize, tracker.requestUncompressedSize
+		if tracker.requestBody != nil {
+			requestSize = tracker.requestBody.count()
+			if tracker.requestBody.uncompressed {
+				requestUncompressedSize = requestSize
+			}
+		}
+		if requestSize >= 0 {
+			tracker.clientRequestSizeRecorder.Record(tracker.ctx, requestSize, tracker.labels...)
+		}
+		if requestUncompressedSize >= 0 {
+			tracker.clientRequestUncompressedSizeRecorder.Record(tracker.ctx, requestUncompressedSize, tracker.labels...)
+		}
+		if responseSize := atomic.LoadInt64(&tracker.responseSize); responseSize >= 0 {
+			tracker.clientResponseSizeRecorder.Record(tracker.ctx, responseSize, tracker.labels...)
+		}
 	})
 }
 
+// record is the finalizer of bodyLeak.
+func (l *bodyLeak) record() {
+	l.counter.Add(l.ctx, 1, l.labels...)
+}
+
 func (tracker *tracker) Read(b []byte) (int, error) {
 	n, err := tracker.body.Read(b)
+	atomic.AddInt64(&tracker.responseSize, int64(n))
 	switch err {
 	case nil:
 		return n, nil
@@ -126,3 +669,54 @@ func (tracker *tracker) Close() error {
 	tracker.end()
 	return tracker.body.Close()
 }
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n int64 // accessed atomically, read by the transport concurrently with end
+
+	// uncompressed is whether the bytes counted are also the uncompressed
+	// size of the body.
+	uncompressed bool
+}
+
+// newCountingBody returns a countingBody for the body of r, a request of
+// unknown size, whose uncompressed size is the one returned by
+// requestBodySizes.
+func newCountingBody(r *http.Request, uncompressed int64) *countingBody {
+	return &countingBody{
+		ReadCloser:   r.Body,
+		uncompressed: uncompressed < 0 && !isCompressed(r.Header.Get("Content-Encoding")),
+	}
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(&b.n, int64(n))
+	return n, err
+}
+
+func (b *countingBody) count() int64 {
+	return atomic.LoadInt64(&b.n)
+}
+
+// countingWriterToBody is a countingBody for bodies implementing io.WriterTo,
+// so that io.Copy keeps using their WriteTo method.
+type countingWriterToBody struct {
+	*countingBody
+}
+
+func (b countingWriterToBody) WriteTo(dst io.Writer) (int64, error) {
+	n, err := b.ReadCloser.(io.WriterTo).WriteTo(dst)
+	atomic.AddInt64(&b.n, n)
+	return n, err
+}
+
+// wrap returns the body to replace the counted one with, which implements
+// the same of the optional interfaces, like io.WriterTo, as the counted one.
+func (b *countingBody) wrap() io.ReadCloser {
+	if _, ok := b.ReadCloser.(io.WriterTo); ok {
+		return countingWriterToBody{b}
+	}
+	return b
+}
@@ -0,0 +1,725 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/number"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func countMeasurements(impl *oteltest.MeterImpl, name string) int {
+	var n int
+	for _, m := range oteltest.AsStructs(impl.MeasurementBatches) {
+		if m.Name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestTransportGlobalMeterProviderSwap(t *testing.T) {
+	prev := otel.GetMeterProvider()
+	defer otel.SetMeterProvider(prev)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	first, firstProvider := oteltest.NewMeterProvider()
+	otel.SetMeterProvider(firstProvider)
+
+	c := http.Client{Transport: NewTransport(http.DefaultTransport)}
+	get := func() {
+		res, err := c.Get(ts.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	get()
+	assert.Equal(t, 1, countMeasurements(first, clientRequestDuration))
+
+	second, secondProvider := oteltest.NewMeterProvider()
+	otel.SetMeterProvider(secondProvider)
+
+	get()
+	assert.Equal(t, 1, countMeasurements(first, clientRequestDuration))
+	assert.Equal(t, 1, countMeasurements(second, clientRequestDuration))
+}
+
+func TestTransportConfiguredMeterProviderIgnoresSwap(t *testing.T) {
+	prev := otel.GetMeterProvider()
+	defer otel.SetMeterProvider(prev)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	configured, configuredProvider := oteltest.NewMeterProvider()
+	tr := NewTransport(http.DefaultTransport, WithMeterProvider(configuredProvider))
+
+	global, globalProvider := oteltest.NewMeterProvider()
+	otel.SetMeterProvider(globalProvider)
+	tr.(interface{ Rebuild() }).Rebuild()
+
+	c := http.Client{Transport: tr}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, 1, countMeasurements(configured, clientRequestDuration))
+	assert.Equal(t, 0, countMeasurements(global, clientRequestDuration))
+}
+
+func TestSameMeterProvider(t *testing.T) {
+	_, a := oteltest.NewMeterProvider()
+	_, b := oteltest.NewMeterProvider()
+	assert.True(t, sameMeterProvider(a, a))
+	assert.False(t, sameMeterProvider(a, b))
+
+	var f metric.MeterProvider = meterProviderFunc(func(string, ...metric.MeterOption) metric.Meter {
+		return metric.Meter{}
+	})
+	assert.True(t, sameMeterProvider(f, f))
+	assert.False(t, sameMeterProvider(a, f))
+}
+
+// notifyingMeterImpl signals recorded after every measurement made with its
+// synchronous instrument named name.
+type notifyingMeterImpl struct {
+	*oteltest.MeterImpl
+	name     string
+	recorded chan struct{}
+}
+
+func (m notifyingMeterImpl) NewSyncInstrument(d metric.Descriptor) (metric.SyncImpl, error) {
+	inst, err := m.MeterImpl.NewSyncInstrument(d)
+	if err != nil || d.Name() != m.name {
+		return inst, err
+	}
+	return notifyingSync{SyncImpl: inst, recorded: m.recorded}, nil
+}
+
+type notifyingSync struct {
+	metric.SyncImpl
+	recorded chan struct{}
+}
+
+func (s notifyingSync) RecordOne(ctx context.Context, n number.Number, labels []label.KeyValue) {
+	s.SyncImpl.RecordOne(ctx, n, labels)
+	s.recorded <- struct{}{}
+}
+
+func TestTransportBodyLeakDetection(t *testing.T) {
+	meterimpl, _ := oteltest.NewMeterProvider()
+	impl := notifyingMeterImpl{MeterImpl: meterimpl, name: clientBodyLeaked, recorded: make(chan struct{}, 10)}
+	meterProvider := meterProviderFunc(func(name string, opts ...metric.MeterOption) metric.Meter {
+		return metric.WrapMeterImpl(impl, name, opts...)
+	})
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("body")),
+		}, nil
+	})
+	tr := NewTransport(base, WithMeterProvider(meterProvider), WithBodyLeakDetection(true))
+
+	do := func(closeBody bool) {
+		r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		if closeBody {
+			require.NoError(t, res.Body.Close())
+		}
+	}
+	do(true)
+	do(false)
+
+	timeout := time.After(5 * time.Second)
+	for leaked := false; !leaked; {
+		runtime.GC()
+		select {
+		case <-impl.recorded:
+			leaked = true
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatal("leaked body was not counted")
+		}
+	}
+
+	assert.Equal(t, 1, countMeasurements(meterimpl, clientBodyLeaked))
+	assert.Equal(t, 1, countMeasurements(meterimpl, clientRequestDuration))
+}
+
+func TestTransportBodyLeakDetectionDisabled(t *testing.T) {
+	names := instrumentNames(func(mp metric.MeterProvider) {
+		NewTransport(http.DefaultTransport, WithMeterProvider(mp))
+	})
+	assert.NotContains(t, names, clientBodyLeaked)
+
+	names = instrumentNames(func(mp metric.MeterProvider) {
+		NewTransport(http.DefaultTransport, WithMeterProvider(mp), WithBodyLeakDetection(true))
+	})
+	assert.Contains(t, names, clientBodyLeaked)
+}
+
+func TestTransportStreamedRequestSize(t *testing.T) {
+	testCases := []struct {
+		name         string
+		encoding     string
+		uncompressed bool
+	}{
+		{name: "identity", uncompressed: true},
+		{name: "gzip", encoding: "gzip"},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer ts.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			c := http.Client{Transport: NewTransport(http.DefaultTransport, WithMeterProvider(meterProvider))}
+
+			// A reader of unknown length is sent chunked.
+			body := struct{ io.Reader }{strings.NewReader("streamed body")}
+			req, err := http.NewRequest(http.MethodPost, ts.URL, body)
+			require.NoError(t, err)
+			if tc.encoding != "" {
+				req.Header.Set("Content-Encoding", tc.encoding)
+			}
+			res, err := c.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			sizes := map[string]int64{}
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientRequestSize || m.Name == clientRequestUncompressedSize {
+					sizes[m.Name] = m.Number.AsInt64()
+				}
+			}
+			want := map[string]int64{clientRequestSize: 13}
+			if tc.uncompressed {
+				want[clientRequestUncompressedSize] = 13
+			}
+			assert.Equal(t, want, sizes)
+		})
+	}
+}
+
+func TestTransportStreamedRequestCountedOnce(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	var wrapped bool
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		b, ok := r.Body.(*countingBody)
+		require.True(t, ok)
+		_, wrapped = b.ReadCloser.(*countingBody)
+		_, err := io.Copy(ioutil.Discard, r.Body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, err
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+	)
+
+	body := ioutil.NopCloser(struct{ io.Reader }{strings.NewReader("streamed body")})
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", body)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.False(t, wrapped, "the body must be counted once")
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.Int64Value(13), spans[0].Attributes()[RequestBodySizeKey])
+	var sizes []int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestSize {
+			sizes = append(sizes, m.Number.AsInt64())
+		}
+	}
+	assert.Equal(t, []int64{13}, sizes)
+}
+
+func TestTransportResponseSize(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		read bool
+		opts []Option
+		want []int64
+	}{
+		{name: "read", read: true, want: []int64{11}},
+		{name: "closed unread", want: []int64{0}},
+		{name: "record on response", opts: []Option{WithRecordOnResponse(true)}, want: []int64{11}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Body:          ioutil.NopCloser(strings.NewReader("hello world")),
+					ContentLength: 11,
+				}, nil
+			})
+			tr := NewTransport(base, append([]Option{WithMeterProvider(meterProvider)}, tc.opts...)...)
+
+			r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			if tc.read {
+				_, err = ioutil.ReadAll(res.Body)
+				require.NoError(t, err)
+			}
+			require.NoError(t, res.Body.Close())
+
+			var sizes []int64
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientResponseSize {
+					sizes = append(sizes, m.Number.AsInt64())
+				}
+			}
+			assert.Equal(t, tc.want, sizes)
+		})
+	}
+}
+
+func TestTransportStreamedRequestKeepsRequest(t *testing.T) {
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		_, err := io.Copy(ioutil.Discard, r.Body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, err
+	})
+	tr := NewTransport(base, WithTracerProvider(oteltest.NewTracerProvider()))
+
+	body := ioutil.NopCloser(strings.NewReader("streamed body"))
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", body)
+	require.NoError(t, err)
+
+	_, err = tr.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, body, req.Body, "the request must not be modified")
+}
+
+// writerToBody is a request body of unknown length implementing io.WriterTo,
+// recording whether it was used.
+type writerToBody struct {
+	io.Reader
+	wroteTo bool
+}
+
+func (b *writerToBody) WriteTo(w io.Writer) (int64, error) {
+	b.wroteTo = true
+	return io.Copy(w, b.Reader)
+}
+
+func (b *writerToBody) Close() error { return nil }
+
+func TestTransportStreamedRequestKeepsWriterTo(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		_, err := io.Copy(ioutil.Discard, r.Body)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, err
+	})
+	tr := NewTransport(base, WithMeterProvider(meterProvider))
+
+	body := &writerToBody{Reader: strings.NewReader("streamed body")}
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", body)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.True(t, body.wroteTo, "WriteTo of the body must be used")
+	var sizes []int64
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestSize {
+			sizes = append(sizes, m.Number.AsInt64())
+		}
+	}
+	assert.Equal(t, []int64{13}, sizes)
+}
+
+func TestTransportRootRequests(t *testing.T) {
+	tp := oteltest.NewTracerProvider()
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		opts []Option
+		want []label.KeyValue
+	}{
+		{name: "root", ctx: context.Background(), want: []label.KeyValue{semconv.HTTPHostKey.String("example.com")}},
+		{name: "in span", ctx: ctx},
+		{
+			name: "remote parent",
+			ctx:  trace.ContextWithRemoteSpanContext(context.Background(), parent.SpanContext()),
+		},
+		{
+			name: "operation",
+			ctx:  context.Background(),
+			opts: []Option{WithOperationExtractor(func(*http.Request) string { return "GetUser" })},
+			want: []label.KeyValue{OperationKey.String("GetUser")},
+		},
+		{
+			name: "filtered",
+			ctx:  context.Background(),
+			opts: []Option{WithFilter(func(*http.Request) bool { return false })},
+		},
+		{
+			name: "disabled",
+			ctx:  context.Background(),
+			opts: []Option{WithRootRequestsCounter(false)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			tr := NewTransport(base, append([]Option{WithTracerProvider(tp), WithMeterProvider(meterProvider), WithRootRequestsCounter(true)}, tc.opts...)...)
+
+			r, err := http.NewRequestWithContext(tc.ctx, http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			var got [][]label.KeyValue
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientRootRequests {
+					assert.Equal(t, int64(1), m.Number.AsInt64())
+					var labels []label.KeyValue
+					for k, v := range m.Labels {
+						labels = append(labels, label.KeyValue{Key: k, Value: v})
+					}
+					got = append(got, labels)
+				}
+			}
+			if tc.want == nil {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, [][]label.KeyValue{tc.want}, got)
+			}
+		})
+	}
+}
+
+func TestTransportFiltersOnce(t *testing.T) {
+	for _, accept := range []bool{true, false} {
+		var calls int
+		_, meterProvider := oteltest.NewMeterProvider()
+		base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})
+		tr := NewTransport(base,
+			WithTracerProvider(oteltest.NewTracerProvider()),
+			WithMeterProvider(meterProvider),
+			WithFilter(func(*http.Request) bool {
+				calls++
+				return accept
+			}),
+		)
+
+		r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, 1, calls, "accept: %v", accept)
+	}
+}
+
+func TestTransportRequestOutcomes(t *testing.T) {
+	statuses := map[string]int{"/ok": http.StatusOK, "/missing": http.StatusNotFound, "/broken": http.StatusBadGateway}
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if code, ok := statuses[r.URL.Path]; ok {
+			return &http.Response{StatusCode: code, Body: http.NoBody}, nil
+		}
+		return nil, errors.New("connection refused")
+	})
+
+	type outcome struct {
+		name, statusClass, errorType string
+	}
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want []outcome
+	}{
+		{
+			name: "default classifier",
+			want: []outcome{
+				{clientRequestsSuccess, "2xx", ""},
+				{clientRequestsError, "4xx", "404"},
+				{clientRequestsError, "5xx", "502"},
+				{clientRequestsError, "error", "*errors.errorString"},
+			},
+		},
+		{
+			name: "not found is success",
+			opts: []Option{WithErrorClassifier(func(res *http.Response, err error) string {
+				if res != nil && res.StatusCode == http.StatusNotFound {
+					return ""
+				}
+				return DefaultErrorClassifier(res, err)
+			})},
+			want: []outcome{
+				{clientRequestsSuccess, "2xx", ""},
+				{clientRequestsSuccess, "4xx", ""},
+				{clientRequestsError, "5xx", "502"},
+				{clientRequestsError, "error", "*errors.errorString"},
+			},
+		},
+		{
+			name: "disabled",
+			opts: []Option{WithOutcomeCounters(false)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			tr := NewTransport(base, append([]Option{WithMeterProvider(meterProvider), WithOutcomeCounters(true)}, tc.opts...)...)
+
+			for _, path := range []string{"/ok", "/missing", "/broken", "/unreachable"} {
+				r, err := http.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+				require.NoError(t, err)
+				if res, err := tr.RoundTrip(r); err == nil {
+					require.NoError(t, res.Body.Close())
+				}
+			}
+
+			var got []outcome
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientRequestsSuccess || m.Name == clientRequestsError {
+					assert.Equal(t, int64(1), m.Number.AsInt64())
+					assert.Equal(t, label.StringValue("example.com"), m.Labels[semconv.HTTPHostKey])
+					got = append(got, outcome{m.Name, m.Labels[StatusClassKey].AsString(), m.Labels[ErrorTypeKey].AsString()})
+				}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTransportOpenBodiesGauge(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+		observe := func() []int64 {
+			meterimpl.MeasurementBatches = nil
+			meterimpl.RunAsyncInstruments()
+			var values []int64
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientOpenBodies {
+					assert.Empty(t, m.Labels)
+					values = append(values, m.Number.AsInt64())
+				}
+			}
+			return values
+		}
+		base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("body"))}, nil
+		})
+		tr := NewTransport(base,
+			WithTracerProvider(oteltest.NewTracerProvider()),
+			WithMeterProvider(meterProvider),
+			WithOpenBodiesGauge(enabled),
+		)
+
+		var bodies []io.ReadCloser
+		for i := 0; i < 3; i++ {
+			r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			bodies = append(bodies, res.Body)
+		}
+		if !enabled {
+			assert.Empty(t, observe())
+			continue
+		}
+		assert.Equal(t, []int64{3}, observe())
+
+		require.NoError(t, bodies[0].Close())
+		_, err := ioutil.ReadAll(bodies[1])
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, observe())
+		// Closing a body read to completion does not count it twice.
+		require.NoError(t, bodies[1].Close())
+		require.NoError(t, bodies[2].Close())
+		assert.Equal(t, []int64{0}, observe())
+	}
+}
+
+func TestSortedLabels(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	opts := []Option{
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithMeterProvider(meterProvider),
+		WithOperationExtractor(func(*http.Request) string { return "GetUser" }),
+		WithSortedLabels(true),
+	}
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base, opts...)
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		labeler, _ := LabelerFromContext(r.Context())
+		labeler.Add(label.String("tenant", "a"))
+	}), "server", opts...)
+
+	var keys [][]label.Key
+	for i := 0; i < 2; i++ {
+		meterimpl.MeasurementBatches = nil
+		r, err := http.NewRequest(http.MethodGet, "http://example.com/users/1", nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+		var runKeys []label.Key
+		for _, batch := range meterimpl.MeasurementBatches {
+			for j := 1; j < len(batch.Labels); j++ {
+				assert.LessOrEqual(t, string(batch.Labels[j-1].Key), string(batch.Labels[j].Key), batch.Measurements[0].Instrument.Descriptor().Name())
+			}
+			for _, kv := range batch.Labels {
+				runKeys = append(runKeys, kv.Key)
+			}
+		}
+		keys = append(keys, runKeys)
+	}
+	assert.Equal(t, keys[0], keys[1])
+}
+
+func TestTransportRecordOnResponse(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	body := ioutil.NopCloser(strings.NewReader("body"))
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithRecordOnResponse(true),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+
+	// Recorded before the body is read, which is left unwrapped.
+	assert.Len(t, sr.Completed(), 1)
+	assert.Equal(t, 1, countMeasurements(meterimpl, clientRequestDuration))
+	assert.Equal(t, body, res.Body)
+	require.NoError(t, res.Body.Close())
+}
+
+func TestTransportRecordOnResponseTimeout(t *testing.T) {
+	var ctx context.Context
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		ctx = r.Context()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithRecordOnResponse(true),
+		WithPerRequestTimeout(time.Minute),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+
+	// The timeout bounds reading the body, until it is closed.
+	assert.NoError(t, ctx.Err())
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestTransportNotModifiedTracking(t *testing.T) {
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		code := http.StatusOK
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			code = http.StatusNotModified
+		}
+		return &http.Response{StatusCode: code, Body: http.NoBody}, nil
+	})
+	for _, enabled := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+		tr := NewTransport(base,
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithMeterProvider(meterProvider),
+			WithNotModifiedTracking(enabled),
+		)
+
+		for _, etag := range []string{`"v1"`, `"v0"`, ""} {
+			r, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			require.NoError(t, err)
+			if etag != "" {
+				r.Header.Set("If-None-Match", etag)
+			}
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+		}
+
+		spans := sr.Completed()
+		require.Len(t, spans, 3)
+		var notModified []bool
+		for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+			if m.Name == clientConditionalRequests {
+				assert.Equal(t, label.StringValue("example.com"), m.Labels[semconv.HTTPHostKey])
+				notModified = append(notModified, m.Labels[NotModifiedKey].AsBool())
+			}
+		}
+		if !enabled {
+			for _, s := range spans {
+				assert.NotContains(t, s.Attributes(), NotModifiedKey)
+			}
+			assert.Empty(t, notModified)
+			continue
+		}
+		assert.Equal(t, label.BoolValue(true), spans[0].Attributes()[NotModifiedKey])
+		assert.Equal(t, label.BoolValue(false), spans[1].Attributes()[NotModifiedKey])
+		assert.NotContains(t, spans[2].Attributes(), NotModifiedKey)
+		assert.Equal(t, []bool{true, false}, notModified)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// observations holds the numeric values attached to a request with
+// ContextWithObservation.
+type observations struct {
+	// served is true for the observations of a request being served, which
+	// the requests sent with its context do not record.
+	served bool
+
+	mu     sync.Mutex
+	values []label.KeyValue
+}
+
+func (o *observations) set(name string, value float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kv := label.Float64(name, value)
+	for i := range o.values {
+		if o.values[i].Key == kv.Key {
+			o.values[i] = kv
+			return
+		}
+	}
+	o.values = append(o.values, kv)
+}
+
+func (o *observations) get() []label.KeyValue {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ret := make([]label.KeyValue, len(o.values))
+	copy(ret, o.values)
+	return ret
+}
+
+type observationsContextKeyType int
+
+const observationsContextKey observationsContextKeyType = 0
+
+func observationsFromContext(ctx context.Context) *observations {
+	o, _ := ctx.Value(observationsContextKey).(*observations)
+	return o
+}
+
+// sentObservations returns the observations a request sent with ctx
+// records, those not belonging to a served request.
+func sentObservations(ctx context.Context) *observations {
+	if o := observationsFromContext(ctx); o != nil && !o.served {
+		return o
+	}
+	return nil
+}
+
+// ContextWithObservation attaches value, a number like the items processed
+// or the rows returned by a request, to the request ctx belongs to, under
+// name, and returns a context to use for it. In a request served by a
+// Handler, or by middleware using ContextWithObservations, value is added to
+// the observations of the served request and ctx is returned as is.
+// Otherwise a copy of ctx carrying value is returned, and a Transport sending
+// a request with it records value with the observations of that request.
+//
+// Observations are recorded on the span of the request when it ends, with
+// name as the attribute key, so names must not clash with the other
+// attributes of the span. The last value attached under a name is recorded.
+// They are also recorded by the recorders registered for their name with
+// WithObservationRecorder, labeled like the other metrics of the request.
+// Observations are never used as metric labels, but the cardinality of the
+// names is still the responsibility of the caller: each name is a distinct
+// attribute of the spans.
+func ContextWithObservation(ctx context.Context, name string, value float64) context.Context {
+	if o := observationsFromContext(ctx); o != nil {
+		if o.served {
+			o.set(name, value)
+			return ctx
+		}
+		// Copy the observations of the parent, which may be shared with
+		// other requests.
+		values := o.get()
+		o = &observations{values: values}
+		o.set(name, value)
+		return context.WithValue(ctx, observationsContextKey, o)
+	}
+	o := &observations{}
+	o.set(name, value)
+	return context.WithValue(ctx, observationsContextKey, o)
+}
+
+// ContextWithObservations returns a copy of parent collecting the
+// observations attached with ContextWithObservation to the request served
+// with it, which ObservationsFromContext then returns. The Handler collects
+// the observations of each request it serves this way. Middleware serving
+// requests without a Handler can use it to record observations like the
+// Handler does.
+func ContextWithObservations(parent context.Context) context.Context {
+	return context.WithValue(parent, observationsContextKey, &observations{served: true})
+}
+
+// ObservationsFromContext returns the observations attached to the request
+// ctx belongs to with ContextWithObservation, as labels keyed by their name.
+func ObservationsFromContext(ctx context.Context) []label.KeyValue {
+	return observationsFromContext(ctx).get()
+}
+
+// recordObservations records observations with the recorders registered
+// for their name, labeled with labels.
+func recordObservations(ctx context.Context, recorders map[string]metric.Float64ValueRecorder, observations []label.KeyValue, labels []label.KeyValue) {
+	for _, kv := range observations {
+		if r, ok := recorders[string(kv.Key)]; ok {
+			r.Record(ctx, kv.Value.AsFloat64(), labels...)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestContextWithObservation(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, ObservationsFromContext(ctx))
+
+	parent := ContextWithObservation(ctx, "items", 1)
+	child := ContextWithObservation(parent, "rows", 2)
+	child = ContextWithObservation(child, "items", 3)
+	assert.Equal(t, []label.KeyValue{label.Float64("items", 1)}, ObservationsFromContext(parent))
+	assert.Equal(t, []label.KeyValue{label.Float64("items", 3), label.Float64("rows", 2)}, ObservationsFromContext(child))
+
+	served := ContextWithObservations(ctx)
+	assert.Equal(t, served, ContextWithObservation(served, "items", 4))
+	assert.Equal(t, []label.KeyValue{label.Float64("items", 4)}, ObservationsFromContext(served))
+}
+
+func TestHandlerObservations(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	rows := metric.Must(meterProvider.Meter("test")).NewFloat64ValueRecorder("rows")
+	var outbound []label.KeyValue
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := ContextWithObservation(r.Context(), "rows", 12)
+		ContextWithObservation(ctx, "items", 3)
+		outbound = sentObservations(ctx).get()
+	}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithObservationRecorder("rows", rows),
+	)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// The observations of a served request are not recorded by the
+	// requests it sends.
+	assert.Empty(t, outbound)
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.Float64Value(12), spans[0].Attributes()["rows"])
+	assert.Equal(t, label.Float64Value(3), spans[0].Attributes()["items"])
+	var recorded []oteltest.Measured
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == "rows" {
+			recorded = append(recorded, m)
+		}
+	}
+	require.Len(t, recorded, 1)
+	assert.Equal(t, float64(12), recorded[0].Number.AsFloat64())
+	assert.Equal(t, label.StringValue("test_handler"), recorded[0].Labels["http.server_name"])
+}
+
+func TestTransportObservations(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	items := metric.Must(meterProvider.Meter("test")).NewFloat64ValueRecorder("items")
+	tr := NewTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}),
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithObservationRecorder("items", items),
+	)
+
+	ctx := ContextWithObservation(context.Background(), "items", 7)
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.Float64Value(7), spans[0].Attributes()["items"])
+	var recorded []oteltest.Measured
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == "items" {
+			recorded = append(recorded, m)
+		}
+	}
+	require.Len(t, recorded, 1)
+	assert.Equal(t, float64(7), recorded[0].Number.AsFloat64())
+	assert.Equal(t, label.StringValue("example.com"), recorded[0].Labels["http.host"])
+}
@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocbridge_test
+
+import (
+	"net/http"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats/view"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/ocbridge"
+)
+
+func ExampleNewTransport() {
+	// Keep the OpenCensus client views populated alongside the
+	// OpenTelemetry metrics while dashboards are migrated.
+	if err := view.Register(ochttp.DefaultClientViews...); err != nil {
+		panic(err)
+	}
+	_ = http.Client{
+		Transport: otelhttp.NewTransport(ocbridge.NewTransport(http.DefaultTransport)),
+	}
+}
@@ -0,0 +1,14 @@
+module go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/ocbridge
+
+go 1.14
+
+replace (
+	go.opentelemetry.io/contrib => ../../../../../
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp => ../
+)
+
+require (
+	github.com/stretchr/testify v1.6.1
+	go.opencensus.io v0.22.5
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.14.0
+)
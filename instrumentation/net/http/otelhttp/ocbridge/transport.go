@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ocbridge records the OpenCensus HTTP client stats of
+// go.opencensus.io/plugin/ochttp for requests instrumented with otelhttp.
+//
+// It is a temporary bridge for services migrating from OpenCensus: wrapping
+// the base RoundTripper of an otelhttp.Transport with NewTransport keeps the
+// OpenCensus views, and the dashboards built on them, populated while the
+// OpenTelemetry metrics are being adopted. It will be removed once the
+// migration period is over, so it should not be used by new code.
+//
+// The package is a separate module so that OpenCensus is only a dependency
+// of the services that use it.
+package ocbridge // import "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/ocbridge"
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// Transport is an http.RoundTripper recording the ochttp client measures
+// ClientRoundtripLatency, ClientSentBytes and ClientReceivedBytes, tagged
+// with the ochttp KeyClientMethod, KeyClientPath, KeyClientHost and
+// KeyClientStatus keys, for the requests it sends.
+//
+// The measures are only exported for the views registered with
+// go.opencensus.io/stats/view, like ochttp.DefaultClientViews.
+type Transport struct {
+	base http.RoundTripper
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// NewTransport returns a Transport recording OpenCensus stats for the
+// requests sent with base, or http.DefaultTransport if base is nil. It is
+// meant to be wrapped by an otelhttp.Transport, so that both the
+// OpenCensus and the OpenTelemetry metrics are recorded:
+//
+//	otelhttp.NewTransport(ocbridge.NewTransport(http.DefaultTransport))
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper, delegating to the base
+// RoundTripper and recording the OpenCensus stats of the request once its
+// response body is read to the end or closed.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, err := tag.New(r.Context(),
+		tag.Upsert(ochttp.KeyClientMethod, r.Method),
+		tag.Upsert(ochttp.KeyClientPath, r.URL.Path),
+		tag.Upsert(ochttp.KeyClientHost, r.Host),
+	)
+	if err != nil {
+		// The tags are valid unless the request is malformed, which the base
+		// RoundTripper reports better than the stats would.
+		return t.base.RoundTrip(r)
+	}
+	tr := &tracker{
+		ctx:   ctx,
+		start: time.Now(),
+		sent:  r.ContentLength,
+	}
+
+	res, err := t.base.RoundTrip(r)
+	if err != nil {
+		tr.end("error")
+		return res, err
+	}
+	status := strconv.Itoa(res.StatusCode)
+	if res.Body == nil || res.Body == http.NoBody {
+		tr.end(status)
+		return res, nil
+	}
+	res.Body = &body{ReadCloser: res.Body, tracker: tr, status: status}
+	return res, nil
+}
+
+// tracker records the stats of a request once.
+type tracker struct {
+	ctx      context.Context
+	start    time.Time
+	sent     int64
+	received int64
+	once     sync.Once
+}
+
+func (tr *tracker) end(status string) {
+	tr.once.Do(func() {
+		ctx, err := tag.New(tr.ctx, tag.Upsert(ochttp.KeyClientStatus, status))
+		if err != nil {
+			return
+		}
+		ms := []stats.Measurement{
+			ochttp.ClientRoundtripLatency.M(float64(time.Since(tr.start)) / float64(time.Millisecond)),
+			ochttp.ClientReceivedBytes.M(atomic.LoadInt64(&tr.received)),
+		}
+		if tr.sent >= 0 {
+			ms = append(ms, ochttp.ClientSentBytes.M(tr.sent))
+		}
+		stats.Record(ctx, ms...)
+	})
+}
+
+// body counts the bytes read from a response body and ends its tracker
+// when it is read to the end or closed.
+type body struct {
+	io.ReadCloser
+	tracker *tracker
+	status  string
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(&b.tracker.received, int64(n))
+	if err == io.EOF {
+		b.tracker.end(b.status)
+	}
+	return n, err
+}
+
+func (b *body) Close() error {
+	b.tracker.end(b.status)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ocbridge
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func registerViews(t *testing.T) {
+	views := []*view.View{
+		ochttp.ClientCompletedCount,
+		ochttp.ClientReceivedBytesDistribution,
+		ochttp.ClientSentBytesDistribution,
+	}
+	require.NoError(t, view.Register(views...))
+	t.Cleanup(func() { view.Unregister(views...) })
+}
+
+func status(t *testing.T, row *view.Row) string {
+	for _, tg := range row.Tags {
+		if tg.Key == ochttp.KeyClientStatus {
+			return tg.Value
+		}
+	}
+	t.Fatalf("row without %s tag: %v", ochttp.KeyClientStatus.Name(), row)
+	return ""
+}
+
+func TestTransport(t *testing.T) {
+	registerViews(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	c := http.Client{Transport: otelhttp.NewTransport(NewTransport(nil))}
+	res, err := c.Post(srv.URL+"/users", "text/plain", strings.NewReader("world!"))
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	rows, err := view.RetrieveData(ochttp.ClientCompletedCount.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "201", status(t, rows[0]))
+	assert.Equal(t, int64(1), rows[0].Data.(*view.CountData).Value)
+	assert.Contains(t, rows[0].Tags, tag.Tag{Key: ochttp.KeyClientMethod, Value: http.MethodPost})
+
+	rows, err = view.RetrieveData(ochttp.ClientReceivedBytesDistribution.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 5.0, rows[0].Data.(*view.DistributionData).Sum())
+
+	rows, err = view.RetrieveData(ochttp.ClientSentBytesDistribution.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 6.0, rows[0].Data.(*view.DistributionData).Sum())
+}
+
+func TestTransportError(t *testing.T) {
+	registerViews(t)
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = NewTransport(base).RoundTrip(r)
+	require.Error(t, err)
+
+	rows, err := view.RetrieveData(ochttp.ClientCompletedCount.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "error", status(t, rows[0]))
+}
+
+func TestTransportRecordsOnce(t *testing.T) {
+	registerViews(t)
+	base := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("ok"))}, nil
+	})
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := NewTransport(base).RoundTrip(r)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	rows, err := view.RetrieveData(ochttp.ClientCompletedCount.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(1), rows[0].Data.(*view.CountData).Value)
+}
@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+)
+
+type originatingRouteContextKeyType int
+
+const originatingRouteContextKey originatingRouteContextKeyType = 0
+
+// ContextWithOriginatingRoute returns a copy of parent carrying route, the
+// route of the server endpoint handling the request parent belongs to.
+// Server instrumentation should use it so that outbound requests made while
+// handling a request can be grouped by the endpoint that triggered them, see
+// WithOriginatingRoute. route should be a route template, like "/users/{id}",
+// rather than a request path, to keep the number of distinct values low.
+func ContextWithOriginatingRoute(parent context.Context, route string) context.Context {
+	return context.WithValue(parent, originatingRouteContextKey, route)
+}
+
+// OriginatingRouteFromContext returns the route stored in ctx with
+// ContextWithOriginatingRoute. The second return value is false if ctx
+// carries no route.
+func OriginatingRouteFromContext(ctx context.Context) (string, bool) {
+	route, ok := ctx.Value(originatingRouteContextKey).(string)
+	return route, ok
+}
@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+)
+
+// Limits of the W3C Baggage header, see https://www.w3.org/TR/baggage/#limits.
+const (
+	maxBaggageMembers     = 180
+	maxBaggageBytes       = 8192
+	maxBaggageMemberBytes = 4096
+)
+
+// baggageMemberSize returns the size of the encoding of kv in the baggage
+// header, as the propagation.Baggage propagator encodes it.
+func baggageMemberSize(kv label.KeyValue) int {
+	return len(url.QueryEscape(strings.TrimSpace(string(kv.Key)))) + 1 +
+		len(url.QueryEscape(strings.TrimSpace(kv.Value.Emit())))
+}
+
+// outboundBaggage returns the entries of WithOutboundBaggage, merged with
+// those of previous calls, sorted by key, leaving out and reporting entries
+// with an empty key or that exceed the size limit of a baggage member.
+func outboundBaggage(prev []label.KeyValue, entries map[string]string) []label.KeyValue {
+	merged := make(map[label.Key]label.KeyValue, len(prev)+len(entries))
+	for _, kv := range prev {
+		merged[kv.Key] = kv
+	}
+	for k, v := range entries {
+		kv := label.String(k, v)
+		if strings.TrimSpace(k) == "" || baggageMemberSize(kv) > maxBaggageMemberBytes {
+			otel.Handle(fmt.Errorf("otelhttp: invalid outbound baggage entry %q", k))
+			continue
+		}
+		merged[kv.Key] = kv
+	}
+	out := make([]label.KeyValue, 0, len(merged))
+	for _, kv := range merged {
+		out = append(out, kv)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// withOutboundBaggage returns a copy of ctx with the baggage entries added,
+// except those whose key is already set in the baggage of ctx and those that
+// would make the baggage exceed the limits of the header.
+func withOutboundBaggage(ctx context.Context, entries []label.KeyValue) context.Context {
+	if len(entries) == 0 {
+		return ctx
+	}
+	set := baggage.Set(ctx)
+	members, size := set.Len(), 0
+	for iter := set.Iter(); iter.Next(); {
+		size += baggageMemberSize(iter.Label()) + 1 // and a comma
+	}
+	var add []label.KeyValue
+	for _, kv := range entries {
+		if set.HasValue(kv.Key) {
+			continue
+		}
+		s := baggageMemberSize(kv) + 1
+		if members+1 > maxBaggageMembers || size+s-1 > maxBaggageBytes {
+			continue
+		}
+		members++
+		size += s
+		add = append(add, kv)
+	}
+	if len(add) == 0 {
+		return ctx
+	}
+	return baggage.ContextWithValues(ctx, add...)
+}
+
+// headerBaggageEntry promotes the values of a request header to a baggage
+// entry, see WithRequestHeaderToBaggage.
+type headerBaggageEntry struct {
+	header string
+	key    label.Key
+}
+
+// requestHeaderBaggage returns the mapping of WithRequestHeaderToBaggage,
+// with canonical header names, merged with those of previous calls, sorted
+// by header, leaving out and reporting entries with an empty header or key.
+func requestHeaderBaggage(prev []headerBaggageEntry, mapping map[string]string) []headerBaggageEntry {
+	merged := make(map[string]label.Key, len(prev)+len(mapping))
+	for _, e := range prev {
+		merged[e.header] = e.key
+	}
+	for header, key := range mapping {
+		if strings.TrimSpace(header) == "" || strings.TrimSpace(key) == "" {
+			otel.Handle(fmt.Errorf("otelhttp: invalid request header baggage mapping %q to %q", header, key))
+			continue
+		}
+		merged[http.CanonicalHeaderKey(header)] = label.Key(key)
+	}
+	out := make([]headerBaggageEntry, 0, len(merged))
+	for header, key := range merged {
+		out = append(out, headerBaggageEntry{header: header, key: key})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].header < out[j].header })
+	return out
+}
+
+// headerBaggage returns the baggage entries for the headers of h promoted
+// by entries, with the values of a header joined by commas. Headers absent
+// from h, and values exceeding the size limit of a baggage member, are left
+// out. Of several headers promoted to the same key the first one present
+// wins.
+func headerBaggage(h http.Header, entries []headerBaggageEntry) []label.KeyValue {
+	if len(entries) == 0 {
+		return nil
+	}
+	var out []label.KeyValue
+	seen := make(map[label.Key]bool, len(entries))
+	for _, e := range entries {
+		values := h.Values(e.header)
+		if len(values) == 0 || seen[e.key] {
+			continue
+		}
+		kv := e.key.String(strings.Join(values, ","))
+		if baggageMemberSize(kv) > maxBaggageMemberBytes {
+			continue
+		}
+		seen[e.key] = true
+		out = append(out, kv)
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestOutboundBaggage(t *testing.T) {
+	var got context.Context
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = propagation.Baggage{}.Extract(context.Background(), r.Header)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithPropagators(propagation.Baggage{}),
+		WithOutboundBaggage(map[string]string{"service.name": "checkout", "service.version": "1.0"}),
+		WithOutboundBaggage(map[string]string{"service.version": "1.1", "": "empty key"}),
+	)
+
+	ctx := baggage.ContextWithValues(context.Background(), label.String("service.name", "caller"), label.String("user", "42"))
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	set := baggage.Set(got)
+	assert.Equal(t, []label.KeyValue{
+		label.String("service.name", "caller"),
+		label.String("service.version", "1.1"),
+		label.String("user", "42"),
+	}, set.ToSlice())
+	// The baggage of the request context is left as it is.
+	assert.Equal(t, label.INVALID, baggage.Value(r.Context(), "service.version").Type())
+}
+
+func TestOutboundBaggageLimits(t *testing.T) {
+	entries := []label.KeyValue{label.String("a", "1"), label.String("b", "2")}
+
+	var full []label.KeyValue
+	for i := 0; i < maxBaggageMembers; i++ {
+		full = append(full, label.String(fmt.Sprintf("k%d", i), "v"))
+	}
+	ctx := withOutboundBaggage(baggage.ContextWithValues(context.Background(), full...), entries)
+	set := baggage.Set(ctx)
+	assert.Equal(t, maxBaggageMembers, set.Len())
+
+	// One entry fits in the remaining bytes, the other does not.
+	large := label.String("large", strings.Repeat("x", maxBaggageBytes-len("large=")-len(",a=1")))
+	ctx = withOutboundBaggage(baggage.ContextWithValues(context.Background(), large), entries)
+	set = baggage.Set(ctx)
+	assert.True(t, set.HasValue("a"))
+	assert.False(t, set.HasValue("b"))
+}
+
+func TestRequestHeaderToBaggage(t *testing.T) {
+	var got context.Context
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = propagation.Baggage{}.Extract(context.Background(), r.Header)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base,
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithPropagators(propagation.Baggage{}),
+		WithRequestHeaderToBaggage(map[string]string{"x-tenant-id": "tenant", "X-Region": "region"}),
+		WithRequestHeaderToBaggage(map[string]string{"X-Feature": "feature", "X-User": "user"}),
+		WithOutboundBaggage(map[string]string{"tenant": "default", "service.name": "checkout"}),
+	)
+
+	ctx := baggage.ContextWithValues(context.Background(), label.String("user", "42"))
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	r.Header.Set("X-Tenant-Id", "acme")
+	r.Header.Add("X-Feature", "a")
+	r.Header.Add("X-Feature", "b")
+	r.Header.Set("X-User", "7")
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	set := baggage.Set(got)
+	assert.Equal(t, []label.KeyValue{
+		label.String("feature", "a,b"),
+		label.String("service.name", "checkout"),
+		label.String("tenant", "acme"),
+		label.String("user", "42"),
+	}, set.ToSlice())
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.22
+// +build go1.22
+
+// The module predates Go 1.22, so the test binary would otherwise default to
+// the http.ServeMux of Go 1.21, which does not set patterns.
+//go:debug httpmuxgo121=0
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestPatternRoute(t *testing.T) {
+	assert.Equal(t, "/items/{id}", patternRoute("/items/{id}"))
+	assert.Equal(t, "/items/{id}", patternRoute("GET /items/{id}"))
+	assert.Equal(t, "/items/", patternRoute("GET example.com/items/"))
+	assert.Equal(t, "", patternRoute(""))
+}
+
+func TestServeMuxPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, tc := range []struct {
+		name     string
+		enabled  bool
+		path     string
+		spanName string
+		route    string
+	}{
+		{name: "pattern", enabled: true, path: "/items/42", spanName: "/items/{id}", route: "/items/{id}"},
+		{name: "no pattern", enabled: true, path: "/other", spanName: "/other"},
+		{name: "disabled", path: "/items/42", spanName: "test_handler"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			h := NewHandler(mux, "test_handler",
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithServeMuxPattern(tc.enabled),
+			)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.spanName, spans[0].Name())
+			if tc.route == "" {
+				assert.NotContains(t, spans[0].Attributes(), semconv.HTTPRouteKey)
+			} else {
+				assert.Equal(t, label.StringValue(tc.route), spans[0].Attributes()[semconv.HTTPRouteKey])
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// redactedQueryValue replaces the values of redacted query parameters.
+const redactedQueryValue = "REDACTED"
+
+// secretQueryParameterNames are the substrings of the names of the query
+// parameters DefaultQueryRedactor redacts.
+var secretQueryParameterNames = []string{
+	"auth",
+	"credential",
+	"key",
+	"passwd",
+	"password",
+	"secret",
+	"session",
+	"signature",
+	"token",
+}
+
+// DefaultQueryRedactor reports whether the value of the query parameter
+// named name must be redacted when recorded with WithRecordQueryString. It
+// redacts parameters whose name, in any case, contains "auth", "credential",
+// "key", "passwd", "password", "secret", "session", "signature" or "token",
+// like "access_token" or "X-Amz-Signature", and leaves others, like "page"
+// or "limit", visible.
+func DefaultQueryRedactor(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range secretQueryParameterNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactQuery returns the raw query string raw with the values of the
+// parameters for which redact returns true replaced with "REDACTED". The
+// parameters and their encoding are otherwise kept as they are.
+func redactQuery(raw string, redact func(name string) bool) string {
+	params := strings.Split(raw, "&")
+	for i, param := range params {
+		name := param
+		if j := strings.IndexByte(param, '='); j >= 0 {
+			name = param[:j]
+		}
+		decoded, err := url.QueryUnescape(name)
+		if err != nil {
+			decoded = name
+		}
+		if redact(decoded) {
+			params[i] = name + "=" + redactedQueryValue
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// redactURL returns the URL or request target u with the values of its
+// query parameters redacted like redactQuery does.
+func redactURL(u string, redact func(name string) bool) string {
+	i := strings.IndexByte(u, '?')
+	if i < 0 {
+		return u
+	}
+	query, fragment := u[i+1:], ""
+	if j := strings.IndexByte(query, '#'); j >= 0 {
+		query, fragment = query[:j], query[j:]
+	}
+	return u[:i+1] + redactQuery(query, redact) + fragment
+}
+
+// redactURLAttributes redacts the query string of the http.url and
+// http.target attributes of attrs in place, so that the values the
+// URLQueryKey attribute redacts are not recorded with them either, and
+// returns attrs.
+func redactURLAttributes(attrs []label.KeyValue, redact func(name string) bool) []label.KeyValue {
+	for i, kv := range attrs {
+		if kv.Key == semconv.HTTPURLKey || kv.Key == semconv.HTTPTargetKey {
+			attrs[i] = kv.Key.String(redactURL(kv.Value.AsString(), redact))
+		}
+	}
+	return attrs
+}
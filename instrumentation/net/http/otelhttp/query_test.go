@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedactQuery(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want string
+	}{
+		{raw: "page=2&limit=10", want: "page=2&limit=10"},
+		{raw: "page=2&access_token=abc", want: "page=2&access_token=REDACTED"},
+		{raw: "X-Amz-Signature=abc&X-Amz-Expires=60", want: "X-Amz-Signature=REDACTED&X-Amz-Expires=60"},
+		{raw: "api%5Fkey=abc&q=a+b", want: "api%5Fkey=REDACTED&q=a+b"},
+		{raw: "password&flag", want: "password=REDACTED&flag"},
+		{raw: "token=a&token=b", want: "token=REDACTED&token=REDACTED"},
+	} {
+		t.Run(tc.raw, func(t *testing.T) {
+			assert.Equal(t, tc.want, redactQuery(tc.raw, DefaultQueryRedactor))
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{url: "http://example.com/", want: "http://example.com/"},
+		{url: "http://example.com/?token=abc", want: "http://example.com/?token=REDACTED"},
+		{url: "/users?page=2&api_key=abc#top", want: "/users?page=2&api_key=REDACTED#top"},
+		{url: "/users?", want: "/users?"},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			assert.Equal(t, tc.want, redactURL(tc.url, DefaultQueryRedactor))
+		})
+	}
+}
+
+func TestRecordQueryStringMetricLabels(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base, WithMeterProvider(meterProvider), WithRecordQueryString(true))
+	r, err := http.NewRequest(http.MethodGet, "http://example.com/?token=abc", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	var found bool
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestDuration {
+			found = true
+			assert.Equal(t, label.StringValue("http://example.com/?token=REDACTED"), m.Labels[semconv.HTTPURLKey])
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRecordQueryString(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		target string
+		opts   []Option
+		want   string
+		// wantTarget is the recorded target, also recorded in the URL.
+		wantTarget string
+	}{
+		{name: "disabled", target: "/?page=2&token=abc", wantTarget: "/?page=2&token=abc"},
+		{
+			name:       "enabled",
+			target:     "/?page=2&token=abc",
+			opts:       []Option{WithRecordQueryString(true)},
+			want:       "page=2&token=REDACTED",
+			wantTarget: "/?page=2&token=REDACTED",
+		},
+		{name: "no query", target: "/", opts: []Option{WithRecordQueryString(true)}, wantTarget: "/"},
+		{
+			name:   "custom redactor",
+			target: "/?page=2&email=a%40example.com",
+			opts: []Option{WithRecordQueryString(true), WithQueryRedactor(func(name string) bool {
+				return name == "email" || DefaultQueryRedactor(name)
+			})},
+			want:       "page=2&email=REDACTED",
+			wantTarget: "/?page=2&email=REDACTED",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			opts := append([]Option{WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr)))}, tc.opts...)
+
+			h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test_handler", opts...)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			r, err := http.NewRequest(http.MethodGet, "http://example.com"+tc.target, nil)
+			require.NoError(t, err)
+			res, err := NewTransport(base, opts...).RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 2)
+			for _, s := range spans {
+				if tc.want == "" {
+					assert.NotContains(t, s.Attributes(), URLQueryKey)
+				} else {
+					assert.Equal(t, label.StringValue(tc.want), s.Attributes()[URLQueryKey])
+				}
+				switch s.SpanKind() {
+				case trace.SpanKindServer:
+					assert.Equal(t, label.StringValue(tc.wantTarget), s.Attributes()[semconv.HTTPTargetKey])
+				case trace.SpanKindClient:
+					assert.Equal(t, label.StringValue("http://example.com"+tc.wantTarget), s.Attributes()[semconv.HTTPURLKey])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanLimiter is a token bucket limiting the number of spans started per
+// second, see WithSpanRateLimit.
+type spanLimiter struct {
+	dropped int64 // the spans not started, accessed atomically
+
+	perSecond float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newSpanLimiter returns a spanLimiter allowing perSecond spans per second,
+// in bursts of up to perSecond spans, or nil if perSecond is not positive.
+func newSpanLimiter(perSecond int) *spanLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &spanLimiter{perSecond: float64(perSecond), tokens: float64(perSecond), last: time.Now()}
+}
+
+// allow returns whether a span can be started now, and counts it as dropped
+// if not. It returns true if l is nil.
+func (l *spanLimiter) allow() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > l.perSecond {
+		l.tokens = l.perSecond
+	}
+	l.last = now
+	allowed := l.tokens >= 1
+	if allowed {
+		l.tokens--
+	}
+	l.mu.Unlock()
+	if !allowed {
+		atomic.AddInt64(&l.dropped, 1)
+	}
+	return allowed
+}
+
+// droppedCount returns the number of spans l did not allow.
+func (l *spanLimiter) droppedCount() int64 {
+	return atomic.LoadInt64(&l.dropped)
+}
+
+// start starts a span with tracer if l allows it. Otherwise the returned
+// span does not record anything and carries the span context of the parent
+// of the span in ctx, local or remote, so that the trace is still propagated
+// to the requests sent with the returned context.
+func (l *spanLimiter) start(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanOption) (context.Context, trace.Span) {
+	if l.allow() {
+		return tracer.Start(ctx, name, opts...)
+	}
+	parent := trace.SpanContextFromContext(ctx)
+	if !parent.IsValid() {
+		parent = trace.RemoteSpanContextFromContext(ctx)
+	}
+	span := droppedSpan{Span: trace.SpanFromContext(context.Background()), parent: parent}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// droppedSpan is the span of a request whose span was not started, see
+// spanLimiter.start.
+type droppedSpan struct {
+	trace.Span // a no-op span
+	parent     trace.SpanContext
+}
+
+func (s droppedSpan) SpanContext() trace.SpanContext {
+	return s.parent
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanLimiter(t *testing.T) {
+	assert.Nil(t, newSpanLimiter(0))
+	var l *spanLimiter
+	assert.True(t, l.allow())
+
+	l = newSpanLimiter(3)
+	var allowed int
+	for i := 0; i < 5; i++ {
+		if l.allow() {
+			allowed++
+		}
+	}
+	assert.Equal(t, 3, allowed)
+	assert.Equal(t, int64(2), l.droppedCount())
+}
+
+// observed returns the last value observed for the instrument named name.
+func observed(t *testing.T, impl *oteltest.MeterImpl, name string) int64 {
+	impl.MeasurementBatches = nil
+	impl.RunAsyncInstruments()
+	var value int64
+	found := false
+	for _, m := range oteltest.AsStructs(impl.MeasurementBatches) {
+		if m.Name == name {
+			value, found = m.Number.AsInt64(), true
+		}
+	}
+	require.True(t, found, name)
+	return value
+}
+
+func TestHandlerSpanRateLimit(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	prop := propagation.TraceContext{}
+	var propagated []trace.SpanContext
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		carrier := http.Header{}
+		prop.Inject(r.Context(), carrier)
+		propagated = append(propagated, trace.RemoteSpanContextFromContext(prop.Extract(context.Background(), carrier)))
+	}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithPropagators(prop),
+		WithSpanRateLimit(2),
+	)
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	for i := 0; i < 5; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Traceparent", parent)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	assert.Len(t, sr.Completed(), 2)
+	assert.Equal(t, 5, countMeasurements(meterimpl, ServerLatency))
+	assert.Equal(t, int64(3), observed(t, meterimpl, ServerSpansDropped))
+	// The requests served without a span still propagate the trace, with
+	// their parent as the parent of the requests they send.
+	require.Len(t, propagated, 5)
+	for _, sc := range propagated {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	}
+	assert.NotEqual(t, "00f067aa0ba902b7", propagated[0].SpanID.String())
+	assert.Equal(t, "00f067aa0ba902b7", propagated[4].SpanID.String())
+}
+
+func TestTransportSpanRateLimit(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	var headers []string
+	tr := NewTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		headers = append(headers, r.Header.Get("Traceparent"))
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}),
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithMeterProvider(meterProvider),
+		WithPropagators(propagation.TraceContext{}),
+		WithSpanRateLimit(1),
+	)
+
+	ctx, parent := oteltest.NewTracerProvider().Tracer("test").Start(context.Background(), "parent")
+	for i := 0; i < 3; i++ {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/", nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, 3, countMeasurements(meterimpl, clientRequestDuration))
+	assert.Equal(t, int64(2), observed(t, meterimpl, clientSpansDropped))
+	require.Len(t, headers, 3)
+	assert.Contains(t, headers[0], spans[0].SpanContext().SpanID.String())
+	assert.Contains(t, headers[2], parent.SpanContext().SpanID.String())
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"sync"
+)
+
+// requestInfo is request scoped state a Handler shares with the handlers it
+// wraps. Its presence in the context of a request also marks the request as
+// instrumented, so nested Handlers do not instrument it again.
+type requestInfo struct {
+	// trimTrailingSlash is whether routes are normalized, see
+	// WithTrailingSlashNormalization. It is set when the requestInfo is
+	// created and never changed.
+	trimTrailingSlash bool
+	// countRejections is whether RecordRejection is counted, see
+	// WithRejectionCounter. It is set when the requestInfo is created and
+	// never changed.
+	countRejections bool
+
+	mu       sync.Mutex
+	route    string
+	rejected bool
+}
+
+type requestInfoContextKeyType int
+
+const requestInfoContextKey requestInfoContextKeyType = 0
+
+func injectRequestInfo(ctx context.Context, info *requestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoContextKey, info)
+}
+
+func requestInfoFromContext(ctx context.Context) (*requestInfo, bool) {
+	info, ok := ctx.Value(requestInfoContextKey).(*requestInfo)
+	return info, ok
+}
+
+// normalizeRoute returns route without its trailing slash if the Handler
+// normalizes routes.
+func (info *requestInfo) normalizeRoute(route string) string {
+	if info.trimTrailingSlash {
+		return trimTrailingSlash(route)
+	}
+	return route
+}
+
+func (info *requestInfo) setRoute(route string) {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	info.route = route
+}
+
+func (info *requestInfo) getRoute() string {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	return info.route
+}
+
+func (info *requestInfo) rejection() (rejected bool, route string) {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	return info.rejected, info.route
+}
+
+// RecordRejection signals the Handler serving the request of ctx that the
+// request was rejected, typically by a concurrency limiter or load shedder.
+// The Handler then counts the request with the http.server.rejected
+// instrument, using the labels of its other metrics and, if the request went
+// through WithRouteTag, the route. It returns false if ctx does not belong
+// to a request served by a Handler configured with WithRejectionCounter, in
+// which case nothing is recorded.
+//
+// The limiter must run inside the Handler, and inside WithRouteTag for the
+// route to be known:
+//
+//	mux.Handle("/users", otelhttp.WithRouteTag("/users", limit(users)))
+//	handler := otelhttp.NewHandler(mux, "server", otelhttp.WithRejectionCounter(true))
+//
+// where limit calls RecordRejection with the request context before
+// replying with a 429 or 503 status.
+func RecordRejection(ctx context.Context) bool {
+	info, ok := requestInfoFromContext(ctx)
+	if !ok || !info.countRejections {
+		return false
+	}
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	info.rejected = true
+	return true
+}
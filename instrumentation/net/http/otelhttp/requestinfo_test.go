@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestRecordRejection(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+	limit := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Shed") != "" {
+				assert.True(t, RecordRejection(r.Context()))
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	var mux http.ServeMux
+	mux.Handle("/users/", WithRouteTag("/users/:id", limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))))
+	h := NewHandler(&mux, "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithMeterProvider(meterProvider),
+		WithRejectionCounter(true),
+	)
+
+	for _, shed := range []bool{false, true} {
+		r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		if shed {
+			r.Header.Set("X-Shed", "1")
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	var rejected []oteltest.Measured
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == ServerRejected {
+			rejected = append(rejected, m)
+		}
+	}
+	require.Len(t, rejected, 1)
+	assert.Equal(t, int64(1), rejected[0].Number.AsInt64())
+	assert.Equal(t, label.StringValue("/users/:id"), rejected[0].Labels[semconv.HTTPRouteKey])
+	assert.Equal(t, label.StringValue("test_handler"), rejected[0].Labels[semconv.HTTPServerNameKey])
+}
+
+func TestRecordRejectionWithoutHandler(t *testing.T) {
+	assert.False(t, RecordRejection(context.Background()))
+}
+
+func TestRecordRejectionDisabled(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.False(t, RecordRejection(r.Context()))
+	}), "test_handler",
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithMeterProvider(meterProvider),
+	)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		assert.NotEqual(t, ServerRejected, m.Name)
+	}
+}
+
+func TestTrimTrailingSlash(t *testing.T) {
+	assert.Equal(t, "/users", trimTrailingSlash("/users/"))
+	assert.Equal(t, "/users", trimTrailingSlash("/users"))
+	assert.Equal(t, "/", trimTrailingSlash("/"))
+	assert.Equal(t, "", trimTrailingSlash(""))
+}
+
+func TestTrailingSlashNormalization(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		sr := new(oteltest.StandardSpanRecorder)
+		meterimpl, meterProvider := oteltest.NewMeterProvider()
+
+		var mux http.ServeMux
+		mux.Handle("/users/", WithRouteTag("/users/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			RecordRejection(r.Context())
+		})))
+		h := NewHandler(&mux, "test_handler",
+			WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+			WithMeterProvider(meterProvider),
+			WithTrailingSlashNormalization(enabled),
+			WithRejectionCounter(true),
+		)
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+		want := "/users/"
+		if enabled {
+			want = "/users"
+		}
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		assert.Equal(t, label.StringValue(want), spans[0].Attributes()[semconv.HTTPRouteKey])
+		var found bool
+		for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+			if m.Name == ServerRejected {
+				found = true
+				assert.Equal(t, label.StringValue(want), m.Labels[semconv.HTTPRouteKey])
+			}
+		}
+		assert.True(t, found, "%s was not recorded", ServerRejected)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AddEventToRequestSpan adds an event named name with attrs to the span
+// active in ctx, which, in code passed the context of a request served by
+// the Handler or otelrestful, is the span of the request unless a nested
+// span was started. It does nothing if that span is not recording.
+//
+// Each call adds an event, and exporters and backends drop those beyond the
+// limits of a span: calling it for every log line or in loops makes spans
+// large and costly to export, and attrs should keep high-cardinality values
+// to those worth searching for.
+func AddEventToRequestSpan(ctx context.Context, name string, attrs ...label.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestAddEventToRequestSpan(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddEventToRequestSpan(r.Context(), "cache.miss", label.String("cache.key", "users"))
+		ctx, child := provider.Tracer("test").Start(r.Context(), "query")
+		AddEventToRequestSpan(ctx, "query.done")
+		child.End()
+	}), "test_handler", WithTracerProvider(provider))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "query", spans[0].Name())
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "query.done", spans[0].Events()[0].Name)
+	events := spans[1].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "cache.miss", events[0].Name)
+	assert.Equal(t, label.StringValue("users"), events[0].Attributes["cache.key"])
+}
+
+func TestAddEventToRequestSpanNotRecording(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "job")
+	span.End()
+
+	AddEventToRequestSpan(context.Background(), "ignored")
+	AddEventToRequestSpan(ctx, "ignored")
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Empty(t, spans[0].Events())
+}
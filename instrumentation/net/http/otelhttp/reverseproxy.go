@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+)
+
+// reverseProxyOperation is the operation of the server spans of the Handler
+// returned by InstrumentReverseProxy.
+const reverseProxyOperation = "reverse_proxy"
+
+// InstrumentReverseProxy instruments both sides of proxy. It sets the
+// Transport of proxy to one returned by NewTransport wrapping its current
+// Transport, or http.DefaultTransport if it has none, and returns a Handler
+// serving requests with proxy in a server span named "reverse_proxy", which
+// WithSpanNameFormatter can change. The opts are applied to both the Handler
+// and the Transport.
+//
+// The proxied request derives its context from the inbound one, so its
+// client span is a child of the server span and the trace context sent to
+// the backend replaces the one received. The Director and ModifyResponse of
+// proxy are left unchanged. Its ErrorHandler is wrapped to record the errors
+// of failed proxied requests on the server span too.
+//
+// proxy must not be used on its own, or instrumented again, afterwards.
+func InstrumentReverseProxy(proxy *httputil.ReverseProxy, opts ...Option) http.Handler {
+	base := proxy.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	proxy.Transport = NewTransport(base, opts...)
+
+	errorHandler := proxy.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			// Mirror the default behavior of httputil.ReverseProxy.
+			if proxy.ErrorLog != nil {
+				proxy.ErrorLog.Printf("http: proxy error: %v", err)
+			} else {
+				log.Printf("http: proxy error: %v", err)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		SpanFromContext(r.Context()).RecordError(err)
+		errorHandler(w, r, err)
+	}
+
+	return NewHandler(proxy, reverseProxyOperation, opts...)
+}
@@ -0,0 +1,36 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+func ExampleInstrumentReverseProxy() {
+	backend, err := url.Parse("http://localhost:8080")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Trace the requests received by the proxy and the ones it sends to the
+	// backend, with the latter as children of the former.
+	proxy := httputil.NewSingleHostReverseProxy(backend)
+	handler := InstrumentReverseProxy(proxy)
+	if err := http.ListenAndServe(":7777", handler); err != nil {
+		log.Fatal(err)
+	}
+}
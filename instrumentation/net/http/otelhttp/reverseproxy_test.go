@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInstrumentReverseProxy(t *testing.T) {
+	prop := propagation.TraceContext{}
+	var backendCtx trace.SpanContext
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backendCtx = trace.RemoteSpanContextFromContext(prop.Extract(r.Context(), r.Header))
+	}))
+	defer backend.Close()
+	u, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	sr := new(oteltest.StandardSpanRecorder)
+	h := InstrumentReverseProxy(httputil.NewSingleHostReverseProxy(u),
+		WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+		WithPropagators(prop),
+	)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	client, server := spans[0], spans[1]
+	assert.Equal(t, trace.SpanKindClient, client.SpanKind())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "reverse_proxy", server.Name())
+	assert.Equal(t, server.SpanContext().SpanID, client.ParentSpanID())
+	assert.Equal(t, client.SpanContext().SpanID, backendCtx.SpanID)
+}
+
+func TestInstrumentReverseProxyError(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	proxy := &httputil.ReverseProxy{
+		Director:  func(r *http.Request) { r.URL.Scheme, r.URL.Host = "http", "example.com" },
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) { return nil, assert.AnError }),
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	}
+	h := InstrumentReverseProxy(proxy, WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 2)
+	server := spans[1]
+	require.Len(t, server.Events(), 1)
+	assert.Equal(t, "error", server.Events()[0].Name)
+}
@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+type routeTemplateContextKeyType int
+
+const routeTemplateContextKey routeTemplateContextKeyType = 0
+
+// ContextWithRouteTemplate returns a copy of parent carrying template, the
+// template of the path of the outbound requests sent with the returned
+// context, like "/v1/users/{id}". The Transport names the spans of these
+// requests after their method and template, like "GET /v1/users/{id}",
+// records the template with the URLTemplateKey attribute, and labels their
+// metrics with it instead of their full URL. It is the client side
+// counterpart of server route templates, keeping the number of distinct
+// span names and metric series low. An operation returned by the extractor
+// of WithOperationExtractor takes precedence.
+func ContextWithRouteTemplate(parent context.Context, template string) context.Context {
+	return context.WithValue(parent, routeTemplateContextKey, template)
+}
+
+// RouteTemplateFromContext returns the template stored in ctx with
+// ContextWithRouteTemplate. The second return value is false if ctx carries
+// no template.
+func RouteTemplateFromContext(ctx context.Context) (string, bool) {
+	template, ok := ctx.Value(routeTemplateContextKey).(string)
+	return template, ok
+}
+
+// routeTemplateLabels returns labels, the labels of a request, with the
+// HTTPURLKey label replaced by the URLTemplateKey label for template. labels
+// is not modified.
+func routeTemplateLabels(labels []label.KeyValue, template string) []label.KeyValue {
+	out := make([]label.KeyValue, 0, len(labels))
+	for _, kv := range labels {
+		if kv.Key != semconv.HTTPURLKey {
+			out = append(out, kv)
+		}
+	}
+	return append(out, URLTemplateKey.String(template))
+}
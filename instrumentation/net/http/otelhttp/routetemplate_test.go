@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+func TestRouteTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		ctx       context.Context
+		opts      []Option
+		wantName  string
+		wantLabel label.KeyValue
+	}{
+		{
+			name:      "none",
+			ctx:       context.Background(),
+			wantName:  "GET",
+			wantLabel: semconv.HTTPURLKey.String("http://example.com/v1/users/42"),
+		},
+		{
+			name:      "template",
+			ctx:       ContextWithRouteTemplate(context.Background(), "/v1/users/{id}"),
+			wantName:  "GET /v1/users/{id}",
+			wantLabel: URLTemplateKey.String("/v1/users/{id}"),
+		},
+		{
+			name:      "operation takes precedence",
+			ctx:       ContextWithRouteTemplate(context.Background(), "/v1/users/{id}"),
+			opts:      []Option{WithOperationExtractor(func(*http.Request) string { return "GetUser" })},
+			wantName:  "GetUser",
+			wantLabel: OperationKey.String("GetUser"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			opts := append([]Option{
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithMeterProvider(meterProvider),
+			}, tc.opts...)
+			tr := NewTransport(base, opts...)
+
+			r, err := http.NewRequestWithContext(tc.ctx, http.MethodGet, "http://example.com/v1/users/42", nil)
+			require.NoError(t, err)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tc.wantName, spans[0].Name())
+
+			var found bool
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name != clientRequestDuration {
+					continue
+				}
+				found = true
+				assert.Equal(t, tc.wantLabel.Value, m.Labels[tc.wantLabel.Key])
+				if tc.wantLabel.Key != semconv.HTTPURLKey {
+					assert.NotContains(t, m.Labels, semconv.HTTPURLKey)
+				}
+			}
+			assert.True(t, found)
+		})
+	}
+}
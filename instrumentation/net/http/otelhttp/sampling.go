@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplingHint is a preference about the sampling of the span of a request.
+type SamplingHint int
+
+// Sampling hints that can be returned by the function configured with
+// WithSamplingHint.
+const (
+	// NoSamplingHint leaves the decision to the configured sampler.
+	NoSamplingHint SamplingHint = iota
+	// SampleHint asks for the span to be sampled.
+	SampleHint
+	// DropHint asks for the span not to be sampled.
+	DropHint
+)
+
+// SampleHeader is the request header read by SampleHeaderHint.
+const SampleHeader = "X-OTel-Sample"
+
+// SampleHeaderHint returns the sampling hint requested by the SampleHeader
+// header of r: SampleHint for "1" or "true", DropHint for "0" or "false" and
+// NoSamplingHint otherwise. It is meant to be passed to WithSamplingHint to
+// let callers force the sampling of a request while debugging.
+func SampleHeaderHint(r *http.Request) SamplingHint {
+	switch strings.ToLower(r.Header.Get(SampleHeader)) {
+	case "1", "true":
+		return SampleHint
+	case "0", "false":
+		return DropHint
+	}
+	return NoSamplingHint
+}
+
+// samplingOptions returns the span options conveying hint to the sampler.
+func samplingOptions(hint SamplingHint) []trace.SpanOption {
+	switch hint {
+	case SampleHint:
+		return []trace.SpanOption{
+			trace.WithRecord(),
+			trace.WithAttributes(SamplingPriorityKey.Int(1)),
+		}
+	case DropHint:
+		return []trace.SpanOption{
+			trace.WithAttributes(SamplingPriorityKey.Int(0)),
+		}
+	}
+	return nil
+}
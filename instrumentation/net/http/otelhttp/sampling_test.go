@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestSampleHeaderHint(t *testing.T) {
+	testCases := map[string]SamplingHint{
+		"":      NoSamplingHint,
+		"1":     SampleHint,
+		"TRUE":  SampleHint,
+		"0":     DropHint,
+		"false": DropHint,
+		"maybe": NoSamplingHint,
+	}
+	for value, expected := range testCases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if value != "" {
+			r.Header.Set(SampleHeader, value)
+		}
+		assert.Equal(t, expected, SampleHeaderHint(r), value)
+	}
+}
+
+func TestHandlerSamplingHint(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	h := NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		"test_handler",
+		WithTracerProvider(provider),
+		WithSamplingHint(func(r *http.Request) SamplingHint {
+			if r.URL.Path == "/healthz" {
+				return DropHint
+			}
+			return SampleHeaderHint(r)
+		}),
+	)
+
+	for _, target := range []string{"/healthz", "/debug", "/"} {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if target == "/debug" {
+			r.Header.Set(SampleHeader, "1")
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	spans := sr.Completed()
+	require.Len(t, spans, 3)
+	assert.Equal(t, label.IntValue(0), spans[0].Attributes()[SamplingPriorityKey])
+	assert.Equal(t, label.IntValue(1), spans[1].Attributes()[SamplingPriorityKey])
+	assert.NotContains(t, spans[2].Attributes(), SamplingPriorityKey)
+}
+
+func TestTransportSamplingHint(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithSamplingHint(SampleHeaderHint),
+	)}
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(SampleHeader, "true")
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.IntValue(1), spans[0].Attributes()[SamplingPriorityKey])
+}
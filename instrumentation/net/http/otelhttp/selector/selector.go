@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package selector provides an AggregatorSelector and an ExportKindSelector
+// for the OpenTelemetry metric SDK suited to the instruments of otelhttp.
+// The instrumentation API does not let instruments choose their aggregation
+// or their temporality, so the histogram boundaries and the export kind of
+// an instrument are configured where the SDK is set up.
+package selector // import "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/selector"
+
+import (
+	"go.opentelemetry.io/otel/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	"go.opentelemetry.io/otel/unit"
+)
+
+// DefaultSizeBoundaries returns the histogram boundaries WithSizeBoundaries
+// uses if none are given: the powers of 2 from 64 bytes to 8 MiB.
+func DefaultSizeBoundaries() []float64 {
+	var boundaries []float64
+	for b := 64; b <= 8<<20; b *= 2 {
+		boundaries = append(boundaries, float64(b))
+	}
+	return boundaries
+}
+
+type sizeSelector struct {
+	base       export.AggregatorSelector
+	boundaries []float64
+}
+
+var _ export.AggregatorSelector = sizeSelector{}
+
+// WithSizeBoundaries returns an AggregatorSelector aggregating the
+// ValueRecorder instruments measured in bytes, like the
+// "http.client.request.size", "http.client.response.size",
+// "http.server.request.body.size" and "http.server.response.body.size"
+// instruments of otelhttp, with a histogram of the given boundaries, and
+// delegating all other instruments to base. If boundaries is empty, those
+// returned by DefaultSizeBoundaries are used.
+//
+// Byte sizes spread over far more orders of magnitude than durations, so
+// they need boundaries of their own when the base selector, like
+// simple.NewWithHistogramDistribution, uses histograms for durations:
+//
+//	selector.WithSizeBoundaries(simple.NewWithHistogramDistribution(durationBoundaries), nil)
+//
+// The selector applies to all the instruments of the MeterProvider it is
+// used with, not only those of otelhttp.
+func WithSizeBoundaries(base export.AggregatorSelector, boundaries []float64) export.AggregatorSelector {
+	if len(boundaries) == 0 {
+		boundaries = DefaultSizeBoundaries()
+	}
+	return sizeSelector{base: base, boundaries: boundaries}
+}
+
+func (s sizeSelector) AggregatorFor(descriptor *metric.Descriptor, aggPtrs ...*export.Aggregator) {
+	if descriptor.InstrumentKind() != metric.ValueRecorderInstrumentKind || descriptor.Unit() != unit.Bytes {
+		s.base.AggregatorFor(descriptor, aggPtrs...)
+		return
+	}
+	aggs := histogram.New(len(aggPtrs), descriptor, s.boundaries)
+	for i := range aggPtrs {
+		*aggPtrs[i] = &aggs[i]
+	}
+}
+
+// instrumentationName is the name of the instrumentation otelhttp creates
+// its instruments with.
+const instrumentationName = "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+type temporalitySelector struct {
+	base  export.ExportKindSelector
+	kind  export.ExportKind
+	names map[string]bool
+}
+
+var _ export.ExportKindSelector = temporalitySelector{}
+
+// WithMetricTemporality returns an ExportKindSelector exporting the
+// instruments of otelhttp with kind, export.DeltaExportKind or
+// export.CumulativeExportKind, and delegating all other instruments to
+// base. The instruments of other instrumentations, like otelrestful, are
+// exported with kind too if their instrumentation names are given.
+//
+// Backends aggregating the values they receive themselves, like StatsD and
+// DogStatsD, and some OTLP backends, prefer deltas, which also spare the SDK
+// from keeping the totals of every label set in memory. Backends scraping
+// totals, like Prometheus, need cumulative values. The selector is used
+// where the export kind is chosen, by the processor and the exporter:
+//
+//	temporality := selector.WithMetricTemporality(export.CumulativeExportKindSelector(), export.DeltaExportKind)
+//	processor := basic.New(simple.NewWithInexpensiveDistribution(), temporality)
+//
+// Without it, the export kind is the one the exporter selects for all
+// instruments.
+func WithMetricTemporality(base export.ExportKindSelector, kind export.ExportKind, instrumentationNames ...string) export.ExportKindSelector {
+	names := map[string]bool{instrumentationName: true}
+	for _, name := range instrumentationNames {
+		names[name] = true
+	}
+	return temporalitySelector{base: base, kind: kind, names: names}
+}
+
+func (s temporalitySelector) ExportKindFor(descriptor *metric.Descriptor, aggregatorKind aggregation.Kind) export.ExportKind {
+	if !s.names[descriptor.InstrumentationName()] {
+		return s.base.ExportKindFor(descriptor, aggregatorKind)
+	}
+	return s.kind
+}
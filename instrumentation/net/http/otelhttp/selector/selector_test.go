@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp/selector"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/oteltest"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/metric/controller/pull"
+	"go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+)
+
+func TestWithSizeBoundaries(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		boundaries []float64
+		want       []float64
+	}{
+		{name: "default", want: selector.DefaultSizeBoundaries()},
+		{name: "custom", boundaries: []float64{1024, 65536}, want: []float64{1024, 65536}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := pull.New(basic.New(
+				selector.WithSizeBoundaries(simple.NewWithInexpensiveDistribution(), tc.boundaries),
+				export.CumulativeExportKindSelector(),
+			))
+			opts := []otelhttp.Option{
+				otelhttp.WithMeterProvider(controller.MeterProvider()),
+				otelhttp.WithTracerProvider(oteltest.NewTracerProvider()),
+			}
+
+			h := otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = ioutil.ReadAll(r.Body)
+				_, _ = w.Write([]byte("ok"))
+			}), "server", append(opts, otelhttp.WithRequestBodySize(true))...)
+			ts := httptest.NewServer(h)
+			defer ts.Close()
+			c := http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport, opts...)}
+
+			res, err := c.Post(ts.URL, "text/plain", strings.NewReader(strings.Repeat("a", 100)))
+			require.NoError(t, err)
+			_, err = ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			require.NoError(t, controller.Collect(context.Background()))
+			histograms := map[string][]float64{}
+			other := map[string]bool{}
+			require.NoError(t, controller.ForEach(export.CumulativeExportKindSelector(), func(rec export.Record) error {
+				name := rec.Descriptor().Name()
+				if h, ok := rec.Aggregation().(aggregation.Histogram); ok {
+					buckets, err := h.Histogram()
+					require.NoError(t, err)
+					histograms[name] = buckets.Boundaries
+				} else {
+					other[name] = true
+				}
+				return nil
+			}))
+
+			assert.Equal(t, tc.want, histograms["http.client.request.size"])
+			assert.Equal(t, tc.want, histograms["http.client.response.size"])
+			assert.Equal(t, tc.want, histograms[otelhttp.RequestBodySize])
+			assert.Equal(t, tc.want, histograms[otelhttp.ResponseBodySize])
+			assert.NotContains(t, histograms, "http.client.duration")
+			assert.True(t, other["http.client.duration"])
+			assert.True(t, other[otelhttp.ServerLatency])
+		})
+	}
+}
+
+func TestWithMetricTemporality(t *testing.T) {
+	temporality := selector.WithMetricTemporality(export.CumulativeExportKindSelector(), export.DeltaExportKind)
+	controller := pull.New(
+		basic.New(simple.NewWithInexpensiveDistribution(), temporality),
+		pull.WithCachePeriod(0),
+	)
+	other := metric.Must(controller.MeterProvider().Meter("other")).NewInt64Counter("other.count")
+	opts := []otelhttp.Option{
+		otelhttp.WithMeterProvider(controller.MeterProvider()),
+		otelhttp.WithTracerProvider(oteltest.NewTracerProvider()),
+	}
+	h := otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+	}), "server", opts...)
+
+	sums := func() map[string]int64 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", strings.NewReader("abc")))
+		other.Add(context.Background(), 3)
+		require.NoError(t, controller.Collect(context.Background()))
+		sums := map[string]int64{}
+		require.NoError(t, controller.ForEach(temporality, func(rec export.Record) error {
+			if s, ok := rec.Aggregation().(aggregation.Sum); ok {
+				sum, err := s.Sum()
+				require.NoError(t, err)
+				sums[rec.Descriptor().Name()] = sum.AsInt64()
+			}
+			return nil
+		}))
+		return sums
+	}
+	first, second := sums(), sums()
+	// The otelhttp counter is exported as deltas, the other one as totals.
+	assert.Equal(t, int64(3), first[otelhttp.RequestContentLength])
+	assert.Equal(t, int64(3), second[otelhttp.RequestContentLength])
+	assert.Equal(t, int64(3), first["other.count"])
+	assert.Equal(t, int64(6), second["other.count"])
+}
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextWithSpan returns a copy of parent holding span, which
+// SpanFromContext then returns. The Handler stores the span of each request
+// it serves this way, with trace.ContextWithSpan, so the span is found by any
+// middleware reading it with this package or with the trace package, and
+// this package uses no private context key for it.
+func ContextWithSpan(parent context.Context, span trace.Span) context.Context {
+	return trace.ContextWithSpan(parent, span)
+}
+
+// SpanFromContext returns the span stored in ctx, like the span of the
+// request a Handler serves with ctx. A non-recording span is returned if
+// ctx holds none, so the result is always safe to use.
+func SpanFromContext(ctx context.Context) trace.Span {
+	return trace.SpanFromContext(ctx)
+}
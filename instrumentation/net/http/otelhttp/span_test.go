@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanFromContext(t *testing.T) {
+	assert.False(t, SpanFromContext(context.Background()).IsRecording())
+
+	sr := new(oteltest.StandardSpanRecorder)
+	var span trace.Span
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span = SpanFromContext(r.Context())
+		assert.Equal(t, trace.SpanFromContext(r.Context()), span)
+	}), "test_handler", WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, spans[0].SpanContext(), span.SpanContext())
+
+	ctx := ContextWithSpan(context.Background(), span)
+	assert.Equal(t, span, SpanFromContext(ctx))
+	assert.Equal(t, span, trace.SpanFromContext(ctx))
+}
+
+func TestContextWithLabeler(t *testing.T) {
+	_, ok := LabelerFromContext(context.Background())
+	assert.False(t, ok)
+
+	l := &Labeler{}
+	got, ok := LabelerFromContext(ContextWithLabeler(context.Background(), l))
+	require.True(t, ok)
+	got.Add(label.String("test", "label"))
+	assert.Equal(t, []label.KeyValue{label.String("test", "label")}, l.Get())
+}
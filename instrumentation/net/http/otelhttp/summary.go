@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// summaryReservoirSize is the maximum number of durations kept per label set
+// to estimate the quantiles of a latencySummary.
+const summaryReservoirSize = 1024
+
+// latencySummary estimates quantiles of the durations recorded per label set
+// over each collection interval. Each label set keeps a uniform random
+// sample of at most summaryReservoirSize durations, so the memory used is
+// bounded per label set and the estimates are exact as long as no more
+// durations are recorded in an interval.
+type latencySummary struct {
+	quantiles []float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	sets map[label.Distinct]*reservoir
+}
+
+type reservoir struct {
+	labels  []label.KeyValue
+	samples []float64
+	count   int64
+}
+
+func newLatencySummary(quantiles []float64) *latencySummary {
+	return &latencySummary{
+		quantiles: quantiles,
+		rand:      rand.New(rand.NewSource(rand.Int63())),
+		sets:      make(map[label.Distinct]*reservoir),
+	}
+}
+
+// record adds the duration value to the sample of its label set.
+func (s *latencySummary) record(value float64, labels []label.KeyValue) {
+	set := label.NewSet(labels...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.sets[set.Equivalent()]
+	if !ok {
+		r = &reservoir{labels: labels}
+		s.sets[set.Equivalent()] = r
+	}
+	r.count++
+	if len(r.samples) < summaryReservoirSize {
+		r.samples = append(r.samples, value)
+	} else if i := s.rand.Int63n(r.count); i < summaryReservoirSize {
+		r.samples[i] = value
+	}
+}
+
+// observe reports the quantiles of the durations recorded since the last
+// call, and starts a new interval.
+func (s *latencySummary) observe(_ context.Context, result metric.Float64ObserverResult) {
+	s.mu.Lock()
+	sets := s.sets
+	s.sets = make(map[label.Distinct]*reservoir, len(sets))
+	s.mu.Unlock()
+
+	for _, r := range sets {
+		sort.Float64s(r.samples)
+		for _, q := range s.quantiles {
+			labels := append(r.labels[:len(r.labels):len(r.labels)], QuantileKey.Float64(q))
+			result.Observe(quantile(r.samples, q), labels...)
+		}
+	}
+}
+
+// quantile returns the q-quantile of the sorted, non-empty samples using the
+// nearest-rank method.
+func quantile(samples []float64, q float64) float64 {
+	i := int(math.Ceil(q*float64(len(samples)))) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(samples) {
+		i = len(samples) - 1
+	}
+	return samples[i]
+}
@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/oteltest"
+)
+
+func TestQuantile(t *testing.T) {
+	samples := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, 1.0, quantile(samples, 0))
+	assert.Equal(t, 5.0, quantile(samples, 0.5))
+	assert.Equal(t, 9.0, quantile(samples, 0.9))
+	assert.Equal(t, 10.0, quantile(samples, 0.99))
+	assert.Equal(t, 10.0, quantile(samples, 1))
+	assert.Equal(t, 7.0, quantile([]float64{7}, 0.5))
+}
+
+func TestLatencySummary(t *testing.T) {
+	meterimpl, meter := oteltest.NewMeter()
+	s := newLatencySummary([]float64{0.5, 0.99})
+	_, err := meter.NewFloat64ValueObserver("test", s.observe)
+	require.NoError(t, err)
+
+	a := []label.KeyValue{label.String("set", "a")}
+	b := []label.KeyValue{label.String("set", "b")}
+	for i := 1; i <= 100; i++ {
+		s.record(float64(i), a)
+	}
+	s.record(42, b)
+	meterimpl.RunAsyncInstruments()
+
+	got := map[string]float64{}
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		got[m.Labels["set"].AsString()+"/"+m.Labels[QuantileKey].Emit()] = m.Number.AsFloat64()
+	}
+	assert.Equal(t, map[string]float64{
+		"a/0.5":  50,
+		"a/0.99": 99,
+		"b/0.5":  42,
+		"b/0.99": 42,
+	}, got)
+
+	// A new interval starts after each observation.
+	meterimpl.MeasurementBatches = nil
+	meterimpl.RunAsyncInstruments()
+	assert.Empty(t, meterimpl.MeasurementBatches)
+}
+
+func TestLatencySummaryBoundedMemory(t *testing.T) {
+	s := newLatencySummary([]float64{0.5})
+	for i := 0; i < 10*summaryReservoirSize; i++ {
+		s.record(float64(i), nil)
+	}
+	require.Len(t, s.sets, 1)
+	for _, r := range s.sets {
+		assert.Len(t, r.samples, summaryReservoirSize)
+		assert.Equal(t, int64(10*summaryReservoirSize), r.count)
+	}
+}
+
+func TestTransportLatencySummary(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	tr := NewTransport(base, WithMeterProvider(meterProvider), WithLatencySummary(0.5, 0.9))
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	meterimpl.RunAsyncInstruments()
+
+	var quantiles []string
+	for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+		if m.Name == clientRequestDurationQuantile {
+			quantiles = append(quantiles, m.Labels[QuantileKey].Emit())
+		}
+	}
+	assert.ElementsMatch(t, []string{"0.5", "0.9"}, quantiles)
+}
+
+func TestTransportLatencySummaryDisabled(t *testing.T) {
+	meterimpl, meterProvider := oteltest.NewMeterProvider()
+	NewTransport(http.DefaultTransport, WithMeterProvider(meterProvider))
+	meterimpl.RunAsyncInstruments()
+	assert.Empty(t, meterimpl.MeasurementBatches)
+}
+
+func BenchmarkLatencySummaryRecord(b *testing.B) {
+	s := newLatencySummary([]float64{0.5, 0.99})
+	labels := []label.KeyValue{label.String("set", "a")}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.record(float64(i), labels)
+	}
+}
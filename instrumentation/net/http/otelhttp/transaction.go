@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelhttp
+
+import "context"
+
+type transactionIDContextKeyType int
+
+const transactionIDContextKey transactionIDContextKeyType = 0
+
+// ContextWithTransactionID returns a copy of parent carrying id, the
+// identifier of the business transaction the requests made or served with
+// the returned context belong to. The Transport and the Handler record it on
+// the spans of these requests with the TransactionIDKey attribute, so that
+// all the HTTP calls of a transaction can be grouped in analysis. It is not
+// used as a metric label, as its cardinality is unbounded.
+func ContextWithTransactionID(parent context.Context, id string) context.Context {
+	return context.WithValue(parent, transactionIDContextKey, id)
+}
+
+// TransactionIDFromContext returns the transaction identifier stored in ctx
+// with ContextWithTransactionID. The second return value is false if ctx
+// carries none.
+func TransactionIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(transactionIDContextKey).(string)
+	return id, ok
+}
@@ -16,9 +16,22 @@ package otelhttp
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv"
 	"go.opentelemetry.io/otel/trace"
@@ -34,12 +47,59 @@ type Transport struct {
 	spanStartOptions  []trace.SpanOption
 	filters           []Filter
 	spanNameFormatter func(string, *http.Request) string
+	spanEndHook       func(context.Context, trace.Span, *http.Request, *http.Response, error)
+	errorMaxLen       int
+	callerLocation    bool
+	callerSkip        int
+	requestTimeout    time.Duration
+	contentTypeClass  func(string) string
+	cacheDebug        bool
+	notModified       bool
+	coalescing        bool
+	cacheHits         bool
+	bodyTee           func(context.Context, BodyDirection, []byte)
+	spanLimiter       *spanLimiter
+	samplingHint      func(*http.Request) SamplingHint
+	responseTrailers  []string
+	readStats         bool
+	slowReadThreshold time.Duration
+	streamChunkEvents bool
+	latencyThreshold  time.Duration
+	protocols         *protocolHistory // the newest protocols of the hosts, if WithProtocolDowngradeDetection is used
+	recordOnResponse  bool
+	originatingRoute  bool
+	proxyAttribute    bool
+	errorBodyCapture  int
+	perAttemptSpans   bool
+	headersSize       bool
+	contextAttributes func(context.Context) []label.KeyValue
+	errorClassifier   func(*http.Response, error) string
+	errorStatusRules  []ErrorStatusRule
+	reasonPhrase      bool
+	operation         func(*http.Request) string
+	connConcurrency   *connConcurrency
+	outboundBaggage   []label.KeyValue
+	recordQuery       bool
+	queryRedactor     func(string) bool
+
+	// absoluteTimestamps records the wall clock times requests are sent and
+	// their responses received, see WithAbsoluteTimestamps.
+	absoluteTimestamps bool
+
+	// requestHeaderBaggage are the headers promoted to baggage entries, see
+	// WithRequestHeaderToBaggage.
+	requestHeaderBaggage []headerBaggageEntry
 }
 
 var _ http.RoundTripper = &Transport{}
 
 // NewTransport wraps the provided http.RoundTripper with one that
 // starts a span and injects the span context into the outbound request headers.
+//
+// Unless WithMeterProvider is used, the returned http.RoundTripper records
+// metrics with the global MeterProvider and recreates its instruments when
+// that provider is replaced. It also implements interface{ Rebuild() } to
+// recreate them explicitly.
 func NewTransport(base http.RoundTripper, opts ...Option) http.RoundTripper {
 	t := instrumentedTransport{
 		base: &Transport{
@@ -64,6 +124,46 @@ func (t *Transport) applyConfig(c *config) {
 	t.spanStartOptions = c.SpanStartOptions
 	t.filters = c.Filters
 	t.spanNameFormatter = c.SpanNameFormatter
+	t.spanEndHook = c.SpanEndHook
+	t.errorMaxLen = c.ClientErrorMaxLen
+	t.callerLocation = c.CallerLocation
+	t.callerSkip = c.CallerSkip
+	t.requestTimeout = c.RequestTimeout
+	t.contentTypeClass = c.ContentTypeClassifier
+	t.cacheDebug = c.CacheDebug
+	t.notModified = c.NotModified
+	t.coalescing = c.CoalescedRequests
+	t.cacheHits = c.CacheHits
+	t.bodyTee = c.BodyTee
+	t.spanLimiter = newSpanLimiter(c.SpanRateLimit)
+	t.samplingHint = c.SamplingHint
+	t.responseTrailers = c.ResponseTrailers
+	t.readStats = c.ReadStats
+	t.slowReadThreshold = c.SlowReadThreshold
+	t.streamChunkEvents = c.StreamChunkEvents
+	t.latencyThreshold = c.LatencyThreshold
+	if c.ProtocolDowngrade {
+		t.protocols = newProtocolHistory()
+	}
+	t.recordOnResponse = c.RecordOnResponse
+	t.absoluteTimestamps = c.AbsoluteTimestamps
+	t.requestHeaderBaggage = c.RequestHeaderBaggage
+	t.originatingRoute = c.OriginatingRoute
+	t.proxyAttribute = c.ProxyAttribute
+	t.errorBodyCapture = c.ErrorBodyCapture
+	t.perAttemptSpans = c.PerAttemptSpans
+	t.headersSize = c.HeadersSize
+	t.contextAttributes = c.ContextAttributeExtractor
+	t.errorClassifier = c.ErrorClassifier
+	t.errorStatusRules = c.ErrorStatusRules
+	t.reasonPhrase = c.ReasonPhrase
+	t.operation = c.OperationExtractor
+	t.outboundBaggage = c.OutboundBaggage
+	if c.ConnectionConcurrency {
+		t.connConcurrency = newConnConcurrency()
+	}
+	t.recordQuery = c.RecordQueryString
+	t.queryRedactor = c.QueryRedactor
 }
 
 func defaultTransportFormatter(_ string, r *http.Request) string {
@@ -74,58 +174,611 @@ func defaultTransportFormatter(_ string, r *http.Request) string {
 // before handing the request to the configured base RoundTripper. The created span will
 // end when the response body is closed or when a read from the body returns io.EOF.
 func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, _, err := t.roundTrip(r, t.operationOf(r), t.traces(r), nil)
+	return res, err
+}
+
+// operationOf returns the operation of r, as returned by the configured
+// operation extractor, or an empty string if there is none.
+func (t *Transport) operationOf(r *http.Request) string {
+	if t.operation == nil {
+		return ""
+	}
+	return t.operation(r)
+}
+
+// traces returns whether r is traced, that is accepted by all the filters.
+func (t *Transport) traces(r *http.Request) bool {
 	for _, f := range t.filters {
 		if !f(r) {
-			// Simply pass through to the base RoundTripper if a filter rejects the request
-			return t.rt.RoundTrip(r)
+			return false
 		}
 	}
+	return true
+}
 
-	opts := append([]trace.SpanOption{}, t.spanStartOptions...) // start with the configured options
+// roundTrip implements RoundTrip for a request of the given operation, which
+// may be empty, and traced if the filters accept it. It also returns the
+// class of error of the request, so that the operation, the filters and the
+// class of error are only computed once when metrics are recorded. streamed
+// is the body of r if it is a streamed body already counted for the metrics,
+// so that it is not counted twice.
+func (t *Transport) roundTrip(r *http.Request, operation string, traced bool, streamed *countingBody) (*http.Response, string, error) {
+	if !traced {
+		// Simply pass through to the base RoundTripper if a filter rejects
+		// the request, which is not classified either.
+		res, err := t.rt.RoundTrip(r)
+		return res, "", err
+	}
 
-	ctx, span := t.tracer.Start(r.Context(), t.spanNameFormatter("", r), opts...)
+	name, opts := t.spanStart(r, operation)
+	start := time.Now()
+	ctx, span := t.spanLimiter.start(r.Context(), t.tracer, name, opts...)
+	var logical *attempts
+	if a := attemptsFromContext(ctx); a != nil {
+		// The request is an attempt of a logical request.
+		if resend := a.next(); resend > 0 {
+			span.SetAttributes(ResendCountKey.Int64(resend))
+		}
+	} else if t.perAttemptSpans {
+		ctx, logical = contextWithAttempts(ctx)
+	}
+	ctx, cancel, timeout := t.withTimeout(ctx, span)
+	// http.Client sets the Cancel channel of requests when its Timeout is
+	// set, and closes it once the timeout expires.
+	clientCancel := r.Cancel
+	var held *heldConns
+	if t.connConcurrency != nil && logical == nil {
+		// The connections of a logical request are held by its attempts.
+		held = &heldConns{concurrency: t.connConcurrency}
+	}
+	ctx, coalesced, cache := t.withHooks(ctx, span, r, held)
 
 	r = r.WithContext(ctx)
-	span.SetAttributes(semconv.HTTPClientAttributesFromHTTPRequest(r)...)
-	t.propagators.Inject(ctx, r.Header)
+	streamed = t.setRequestAttributes(span, r, streamed)
+	bagCtx := withOutboundBaggage(ctx, headerBaggage(r.Header, t.requestHeaderBaggage))
+	t.propagators.Inject(withOutboundBaggage(bagCtx, t.outboundBaggage), r.Header)
+	if t.headersSize {
+		span.SetAttributes(RequestHeadersSizeKey.Int64(headersSize(r.Header)))
+	}
+	if t.bodyTee != nil && r.Body != nil && r.Body != http.NoBody {
+		// r is a copy of the request, its GetBody function still returns
+		// bodies that are not teed.
+		r.Body = &teeBody{ReadCloser: r.Body, tee: bodyTee(ctx, t.bodyTee, RequestBodyDirection)}
+	}
 
+	sent := time.Now()
 	res, err := t.rt.RoundTrip(r)
+	if err == nil {
+		res.Body = teeResponseBody(res, bodyTee(ctx, t.bodyTee, ResponseBodyDirection))
+	}
+	if t.absoluteTimestamps {
+		span.SetAttributes(RequestSendTimeKey.Int64(sent.UnixNano()))
+		if err == nil {
+			span.SetAttributes(ResponseReceiveTimeKey.Int64(time.Now().UnixNano()))
+		}
+	}
+	if coalesced.isCoalesced() {
+		span.SetAttributes(CoalescedKey.Bool(true))
+	}
+	if hit, ok := cache.get(); ok {
+		span.SetAttributes(CacheHitKey.Bool(hit))
+	}
+	end := func(res *http.Response, err error) {
+		held.release()
+		logical.summarize(span)
+		t.endSpan(ctx, span, start, r, streamed, res, err)
+	}
+
+	clientTimeout := clientTimedOut(clientCancel, err)
+	// classified is the error passed to the error classifier and the error
+	// status rules.
+	classified := err
+	if clientTimeout {
+		classified = &clientTimeoutError{err: err}
+	}
+	errorType := t.errorClassifier(res, classified)
+	if errorType != "" {
+		span.SetAttributes(ErrorTypeKey.String(errorType))
+	}
 	if err != nil {
-		span.RecordError(err)
-		span.End()
-		return res, err
+		t.setErrorStatus(span, res, err, classified, errorType)
+		setTimeoutSource(ctx, span, err, timeout, clientTimeout)
+		end(nil, err)
+		cancel()
+		return res, errorType, err
+	}
+
+	code := t.setResponseAttributes(span, r, res)
+	if t.recordOnResponse {
+		end(res, nil)
+		if !timeout {
+			cancel()
+			return res, errorType, err
+		}
+		// The per-request timeout still bounds reading the body.
+		res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		return res, errorType, err
+	}
+	res.Body = t.wrapResponseBody(ctx, span, res, code == codes.Error, timeout, func() {
+		if len(t.responseTrailers) > 0 {
+			span.SetAttributes(trailerAttributes(res.Trailer, t.responseTrailers)...)
+		}
+		end(res, nil)
+		cancel()
+	})
+
+	return res, errorType, err
+}
+
+// withHooks returns a copy of ctx holding the httptrace hooks annotating span
+// with the connection of r, whose connections are counted in held, if not
+// nil, and the results the base RoundTripper marks the request with.
+func (t *Transport) withHooks(ctx context.Context, span trace.Span, r *http.Request, held *heldConns) (context.Context, *coalescing, *cacheResult) {
+	ctx = httptrace.WithClientTrace(ctx, t.clientTrace(span, r.URL.Hostname(), held))
+	coalesced := coalescingFromContext(ctx)
+	if coalesced == nil && t.coalescing {
+		ctx, coalesced = contextWithCoalescing(ctx)
+	}
+	cache := cacheResultFromContext(ctx)
+	if cache == nil && t.cacheHits {
+		ctx, cache = contextWithCacheResult(ctx)
+	}
+	return ctx, coalesced, cache
+}
+
+// spanStart returns the name and the options of the span of r, a request of
+// the given operation, which may be empty.
+func (t *Transport) spanStart(r *http.Request, operation string) (string, []trace.SpanOption) {
+	opts := append([]trace.SpanOption{}, t.spanStartOptions...) // start with the configured options
+	if t.samplingHint != nil {
+		opts = append(opts, samplingOptions(t.samplingHint(r))...)
+	}
+	if t.contextAttributes != nil {
+		if attrs := t.contextAttributes(r.Context()); len(attrs) > 0 {
+			opts = append(opts, trace.WithAttributes(attrs...))
+		}
+	}
+
+	if id, ok := TransactionIDFromContext(r.Context()); ok {
+		opts = append(opts, trace.WithAttributes(TransactionIDKey.String(id)))
+	}
+	if cohort, ok := CohortFromContext(r.Context()); ok {
+		opts = append(opts, trace.WithAttributes(CohortKey.String(cohort)))
+	}
+	if t.recordQuery && r.URL.RawQuery != "" {
+		opts = append(opts, trace.WithAttributes(URLQueryKey.String(redactQuery(r.URL.RawQuery, t.queryRedactor))))
+	}
+	if links := linkedSpansFromContext(r.Context()); len(links) > 0 {
+		opts = append(opts, trace.WithLinks(links...))
+	}
+
+	name := t.spanNameFormatter("", r)
+	if operation != "" {
+		name = operation
+		opts = append(opts, trace.WithAttributes(OperationKey.String(operation)))
+	} else if template, ok := RouteTemplateFromContext(r.Context()); ok {
+		method := r.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		name = method + " " + template
+		opts = append(opts, trace.WithAttributes(URLTemplateKey.String(template)))
+	}
+	return name, opts
+}
+
+// withTimeout returns a copy of ctx bounded by the per-request timeout if it
+// has no deadline, along with its cancel function and whether the timeout
+// applies, and records the source of the timeout of the request on span.
+func (t *Transport) withTimeout(ctx context.Context, span trace.Span) (context.Context, context.CancelFunc, bool) {
+	if _, ok := ctx.Deadline(); ok {
+		span.SetAttributes(TimeoutSourceKey.String(TimeoutSourceContext))
+		return ctx, func() {}, false
+	}
+	if t.requestTimeout <= 0 {
+		return ctx, func() {}, false
+	}
+	span.SetAttributes(TimeoutSourceKey.String(TimeoutSourcePerRequest))
+	ctx, cancel := context.WithTimeout(ctx, t.requestTimeout)
+	return ctx, cancel, true
+}
+
+// setRequestAttributes records the attributes of r on span. It wraps the
+// body of r, a copy of the request, to count it if it is a streamed body not
+// counted yet, and returns the streamed body counted, if any.
+func (t *Transport) setRequestAttributes(span trace.Span, r *http.Request, streamed *countingBody) *countingBody {
+	if t.recordQuery {
+		span.SetAttributes(redactURLAttributes(ClientRequestAttributes(r), t.queryRedactor)...)
+	} else {
+		span.SetAttributes(ClientRequestAttributes(r)...)
+	}
+	if r.Host != "" && r.Host != r.URL.Host {
+		// The Host header was overridden, the http.host attribute records
+		// it and leaves out the host the request is actually sent to.
+		span.SetAttributes(ServerAddressKey.String(r.URL.Host), RequestHostKey.String(r.Host))
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		span.SetAttributes(RequestContentTypeKey.String(t.contentTypeClass(ct)))
+	}
+	wire, uncompressed := requestBodySizes(r)
+	if wire >= 0 {
+		span.SetAttributes(RequestBodySizeKey.Int64(wire))
+	} else if streamed == nil && r.Body != nil {
+		// The size of a streamed body is only known once it has been sent,
+		// it is recorded when the span ends.
+		streamed = newCountingBody(r, uncompressed)
+		r.Body = streamed.wrap()
+	}
+	if uncompressed >= 0 {
+		span.SetAttributes(RequestUncompressedSizeKey.Int64(uncompressed))
+	}
+	if t.originatingRoute {
+		if route, ok := OriginatingRouteFromContext(r.Context()); ok {
+			span.SetAttributes(OriginatingRouteKey.String(route))
+		}
+	}
+	if t.proxyAttribute {
+		if proxy := t.proxy(r); proxy != "" {
+			span.SetAttributes(ProxyKey.String(proxy))
+		}
+	}
+	if t.callerLocation && span.IsRecording() {
+		if file, line, ok := callerLocation(t.callerSkip); ok {
+			span.SetAttributes(CodeFilepathKey.String(file), CodeLineNoKey.Int(line))
+		}
+	}
+	return streamed
+}
+
+// setErrorStatus records err, the error of a request that failed without a
+// response, on span and sets its status, from errorType and the error status
+// rules, which are passed classified.
+func (t *Transport) setErrorStatus(span trace.Span, res *http.Response, err, classified error, errorType string) {
+	span.RecordError(err)
+	span.SetAttributes(ClientErrorKey.String(truncate(err.Error(), t.errorMaxLen)))
+	code, msg := codes.Unset, ""
+	if errorType != "" {
+		code, msg = codes.Error, errorType
+	}
+	// The status is set even if unset by a rule, as RecordError sets it to
+	// an error.
+	if code, msg, matched := errorStatus(t.errorStatusRules, res, classified, code, msg); matched || code != codes.Unset {
+		span.SetStatus(code, msg)
+	}
+}
+
+// setTimeoutSource records on span which timeout err, the error of a request
+// sent with ctx, is caused by, if any.
+func setTimeoutSource(ctx context.Context, span trace.Span, err error, timeout, clientTimeout bool) {
+	if timeout && ctx.Err() == context.DeadlineExceeded {
+		span.AddEvent(timeoutEvent)
 	}
+	if clientTimeout {
+		span.SetAttributes(TimeoutSourceKey.String(TimeoutSourceClient))
+	} else if ctx.Err() == nil && isTimeout(err) {
+		// The request timed out before its deadline, so the timeout is one
+		// of the base RoundTripper.
+		span.SetAttributes(TimeoutSourceKey.String(TimeoutSourceTransport))
+	}
+}
 
+// setResponseAttributes records the attributes of res, the response to r,
+// on span, sets its status and returns its code.
+func (t *Transport) setResponseAttributes(span trace.Span, r *http.Request, res *http.Response) codes.Code {
 	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(res.StatusCode)...)
-	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(res.StatusCode))
-	res.Body = &wrappedBody{ctx: ctx, span: span, body: res.Body}
+	code, msg := semconv.SpanStatusFromHTTPStatusCode(res.StatusCode)
+	code, msg, _ = errorStatus(t.errorStatusRules, res, nil, code, msg)
+	span.SetStatus(code, msg)
+	if t.cacheDebug {
+		span.SetAttributes(cacheDebugAttributes(res.Header)...)
+	}
+	if t.notModified && isConditional(r) {
+		span.SetAttributes(NotModifiedKey.Bool(res.StatusCode == http.StatusNotModified))
+	}
+	// Unlike in the TLSHandshakeDone hook, the protocol negotiated with
+	// ALPN is also known for reused connections. It is what the client and
+	// server agreed on, the response may still be sent with another
+	// protocol, like HTTP/1.1 if the base RoundTripper has no HTTP/2 support.
+	if res.TLS != nil && res.TLS.NegotiatedProtocol != "" {
+		span.SetAttributes(TLSALPNKey.String(res.TLS.NegotiatedProtocol))
+	}
+	if t.connConcurrency != nil {
+		span.SetAttributes(NegotiatedProtocolKey.String(res.Proto))
+	}
+	if t.protocols != nil {
+		t.protocols.recordDowngrade(span, r.URL.Host, res)
+	}
+	if t.reasonPhrase {
+		if phrase := reasonPhrase(res); phrase != "" {
+			span.SetAttributes(ResponseReasonPhraseKey.String(phrase))
+		}
+	}
+	return code
+}
 
-	return res, err
+// wrapResponseBody returns the body of res wrapped to call onEnd once it is
+// read to the end or closed. The body of failed requests is captured up to
+// the limit of WithErrorBodyCapture.
+func (t *Transport) wrapResponseBody(ctx context.Context, span trace.Span, res *http.Response, failed, timeout bool, onEnd func()) io.ReadCloser {
+	wb := &wrappedBody{ctx: ctx, span: span, body: res.Body, timeout: timeout, readStats: t.readStats, chunkEvents: t.streamChunkEvents}
+	if failed {
+		wb.captureLimit = t.errorBodyCapture
+	}
+	if t.slowReadThreshold > 0 {
+		wb.slowRead = t.slowReadThreshold
+		wb.lastRead = time.Now()
+	}
+	wb.onEnd = onEnd
+	return wb
+}
+
+// proxy returns the address of the proxy the base RoundTripper sends r
+// through, or an empty string if it is sent directly or the base RoundTripper
+// is not an *http.Transport.
+func (t *Transport) proxy(r *http.Request) string {
+	tr, ok := t.rt.(*http.Transport)
+	if !ok || tr.Proxy == nil {
+		return ""
+	}
+	u, err := tr.Proxy(r)
+	if err != nil || u == nil {
+		return ""
+	}
+	// Leave out any credentials of the proxy URL.
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+}
+
+// reasonPhrase returns the reason phrase of the status line of res, which
+// http.Response.Status holds after the status code.
+func reasonPhrase(res *http.Response) string {
+	return strings.TrimSpace(strings.TrimPrefix(res.Status, strconv.Itoa(res.StatusCode)))
 }
 
+// isConditional returns whether r is a conditional request, which the
+// server answers with a 304 Not Modified if the cached copy of the client is
+// still fresh.
+func isConditional(r *http.Request) bool {
+	return r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+}
+
+// cacheDebugAttributes returns the attributes for the caching related headers
+// present in h.
+func cacheDebugAttributes(h http.Header) []label.KeyValue {
+	var attrs []label.KeyValue
+	for _, kv := range []struct {
+		header string
+		key    label.Key
+	}{
+		{"Age", ResponseAgeKey},
+		{"X-Cache", ResponseXCacheKey},
+		{"Cache-Control", ResponseCacheControlKey},
+	} {
+		if v := h.Get(kv.header); v != "" {
+			attrs = append(attrs, kv.key.String(v))
+		}
+	}
+	return attrs
+}
+
+// trailerAttributes returns the attributes for the trailers of h with the
+// given names.
+func trailerAttributes(h http.Header, names []string) []label.KeyValue {
+	var attrs []label.KeyValue
+	for _, name := range names {
+		if values := h.Values(name); len(values) > 0 {
+			key := "http.response.trailer." + strings.ReplaceAll(strings.ToLower(name), "-", "_")
+			attrs = append(attrs, label.String(key, strings.Join(values, ",")))
+		}
+	}
+	return attrs
+}
+
+// endSpan runs the configured span end hook, if any, and ends the span,
+// started at start, marking whether it exceeded the latency threshold if
+// WithLatencyThresholdTracing is used. The size of the body of r is recorded
+// from streamed, if it is a streamed body.
+func (t *Transport) endSpan(ctx context.Context, span trace.Span, start time.Time, r *http.Request, streamed *countingBody, res *http.Response, err error) {
+	if streamed != nil {
+		n := streamed.count()
+		span.SetAttributes(RequestBodySizeKey.Int64(n))
+		if streamed.uncompressed {
+			span.SetAttributes(RequestUncompressedSizeKey.Int64(n))
+		}
+	}
+	if o := sentObservations(r.Context()); o != nil {
+		span.SetAttributes(o.get()...)
+	}
+	if t.spanEndHook != nil {
+		t.spanEndHook(ctx, span, r, res, err)
+	}
+	if t.latencyThreshold > 0 {
+		span.SetAttributes(LatencyThresholdExceededKey.Bool(time.Since(start) >= t.latencyThreshold))
+	}
+	span.End()
+}
+
+// packageDir is the directory holding the source files of this package.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callerLocation returns the file and line of the code that issued the
+// current request. Frames from net/http and from the non-test source files
+// of this package are ignored before skip further frames are ascended.
+func callerLocation(skip int) (string, int, bool) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	found := false
+	for {
+		frame, more := frames.Next()
+		if !found {
+			found = !strings.HasPrefix(frame.Function, "net/http.") &&
+				(filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go"))
+		}
+		if found {
+			if skip == 0 {
+				return frame.File, frame.Line, true
+			}
+			skip--
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
+
+// truncate returns s shortened to at most n bytes without splitting a
+// multi-byte character. If n is less than or equal to zero s is returned
+// unchanged.
+func truncate(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// isTimeout returns whether err reports a timeout.
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// errorBodyEvent is the name of the span event recording the beginning of
+// the body of error responses, see WithErrorBodyCapture.
+const errorBodyEvent = "http.response.error_body"
+
+// timeoutEvent is the name of the span event added when the timeout
+// configured with WithPerRequestTimeout expires.
+const timeoutEvent = "http.client.timeout"
+
+// slowReadEvent is the name of the span event added when a response body is
+// read slower than the threshold configured with WithSlowReadThreshold.
+const slowReadEvent = "http.client.slow_read"
+
+// chunkEvent is the name of the span event added for each chunk of a
+// response body read, see WithStreamChunkEvents.
+const chunkEvent = "http.client.response.chunk"
+
 type wrappedBody struct {
-	ctx  context.Context
-	span trace.Span
-	body io.ReadCloser
+	ctx     context.Context
+	span    trace.Span
+	body    io.ReadCloser
+	timeout bool   // whether ctx carries the per-request timeout
+	onEnd   func() // must not be nil, ends the span
+
+	// read statistics, only kept if readStats is set
+	readStats bool
+	reads     int64
+	maxRead   int
+
+	// the first bytes read from the body, up to captureLimit, and whether
+	// more bytes were read than captured
+	captureLimit int
+	captured     []byte
+	truncated    bool
+
+	// the threshold of time between reads above which a slowReadEvent is
+	// added, 0 if disabled, and when the last read returned
+	slowRead time.Duration
+	lastRead time.Time
+
+	// whether a chunkEvent is added for each read returning data, the
+	// number of such reads and the bytes they returned
+	chunkEvents bool
+	chunks      int64
+	chunkBytes  int64
+
+	endOnce sync.Once
 }
 
 var _ io.ReadCloser = &wrappedBody{}
 
 func (wb *wrappedBody) Read(b []byte) (int, error) {
+	if wb.slowRead > 0 {
+		if gap := time.Since(wb.lastRead); gap > wb.slowRead {
+			wb.span.AddEvent(slowReadEvent, trace.WithAttributes(SlowReadGapKey.Int64(gap.Microseconds())))
+		}
+	}
 	n, err := wb.body.Read(b)
+	if wb.slowRead > 0 {
+		wb.lastRead = time.Now()
+	}
+	if wb.readStats {
+		wb.reads++
+		if n > wb.maxRead {
+			wb.maxRead = n
+		}
+	}
+	if wb.chunkEvents && n > 0 {
+		wb.chunkBytes += int64(n)
+		wb.span.AddEvent(chunkEvent, trace.WithAttributes(
+			ResponseChunkIndexKey.Int64(wb.chunks),
+			ResponseChunkSizeKey.Int(n),
+			ResponseChunkTotalKey.Int64(wb.chunkBytes),
+		))
+		wb.chunks++
+	}
+	if wb.captureLimit > 0 {
+		c := n
+		if room := wb.captureLimit - len(wb.captured); c > room {
+			c = room
+			wb.truncated = true
+		}
+		wb.captured = append(wb.captured, b[:c]...)
+	}
 
 	switch err {
 	case nil:
 		// nothing to do here but fall through to the return
 	case io.EOF:
-		wb.span.End()
+		wb.end()
 	default:
 		wb.span.RecordError(err)
+		if wb.timeout && wb.ctx.Err() == context.DeadlineExceeded {
+			wb.span.AddEvent(timeoutEvent)
+			wb.timeout = false
+		}
 	}
 	return n, err
 }
 
+// cancelBody calls cancel when the response body is closed, see
+// WithRecordOnResponse.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
 func (wb *wrappedBody) Close() error {
-	wb.span.End()
+	wb.end()
 	return wb.body.Close()
 }
+
+func (wb *wrappedBody) end() {
+	wb.endOnce.Do(func() {
+		if wb.readStats {
+			wb.span.SetAttributes(
+				ResponseReadCountKey.Int64(wb.reads),
+				ResponseMaxReadSizeKey.Int(wb.maxRead),
+			)
+		}
+		if len(wb.captured) > 0 {
+			wb.span.AddEvent(errorBodyEvent, trace.WithAttributes(
+				ResponseBodyKey.String(strings.ToValidUTF8(string(wb.captured), "\uFFFD")),
+				ResponseBodyTruncatedKey.Bool(wb.truncated),
+			))
+		}
+		wb.onEnd()
+	})
+}
@@ -15,7 +15,9 @@
 package otelhttp
 
 import (
+	"context"
 	"net/http"
+	"time"
 )
 
 func ExampleNewTransport() {
@@ -25,3 +27,27 @@ func ExampleNewTransport() {
 		Transport: NewTransport(http.DefaultTransport),
 	}
 }
+
+func ExampleStartRequest() {
+	client := http.Client{Transport: NewTransport(http.DefaultTransport)}
+
+	// The spans of the attempts are children of the span of the logical
+	// request, which records how many times the request was resent.
+	ctx, span := StartRequest(context.Background(), "GetUser")
+	defer span.End()
+	for attempt := 0; attempt < 3; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/users/42", nil)
+		if err != nil {
+			return
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode < http.StatusInternalServerError {
+			return
+		}
+	}
+}
@@ -16,14 +16,27 @@ package otelhttp
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/otel/label"
 	"go.opentelemetry.io/otel/oteltest"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -74,3 +87,800 @@ func TestTransportBasics(t *testing.T) {
 		t.Fatalf("unexpected content: got %s, expected %s", body, content)
 	}
 }
+
+func TestTransportSpanEndHook(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer ts.Close()
+
+	var calls int
+	tr := NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithSpanEndHook(func(ctx context.Context, span trace.Span, r *http.Request, resp *http.Response, err error) {
+			calls++
+			assert.NoError(t, err)
+			if assert.NotNil(t, resp) {
+				assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+			}
+			assert.True(t, span.IsRecording())
+			span.SetAttributes(label.String("hook", "called"))
+		}),
+	)
+
+	c := http.Client{Transport: tr}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	assert.Equal(t, 1, calls)
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue("called"), spans[0].Attributes()["hook"])
+}
+
+func TestTransportSpanEndHookOnError(t *testing.T) {
+	provider := oteltest.NewTracerProvider()
+
+	var hookErr error
+	tr := NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithSpanEndHook(func(ctx context.Context, span trace.Span, r *http.Request, resp *http.Response, err error) {
+			assert.Nil(t, resp)
+			hookErr = err
+		}),
+	)
+
+	c := http.Client{Transport: tr}
+	_, err := c.Get("http://localhost:0")
+	require.Error(t, err)
+	assert.Error(t, hookErr)
+}
+
+func TestTransportErrorAttributes(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	tr := NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithClientErrorMaxLength(10),
+	)
+
+	c := http.Client{Transport: tr}
+	_, err := c.Get("http://localhost:0")
+	require.Error(t, err)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	msg := spans[0].Attributes()[ClientErrorKey].AsString()
+	assert.Len(t, msg, 10)
+	assert.True(t, strings.Contains(err.Error(), msg))
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "error", spans[0].Events()[0].Name)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "hello", truncate("hello", 0))
+	assert.Equal(t, "hello", truncate("hello", 10))
+	assert.Equal(t, "hel", truncate("hello", 3))
+	// "é" is two bytes long and must not be split.
+	assert.Equal(t, "h", truncate("héllo", 2))
+}
+
+func TestTransportCallerLocation(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithCallerLocation(0),
+	)}
+	_, file, line, _ := runtime.Caller(0)
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue(file), spans[0].Attributes()[CodeFilepathKey])
+	assert.Equal(t, label.IntValue(line+1), spans[0].Attributes()[CodeLineNoKey])
+}
+
+func TestTransportPerRequestTimeout(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	block := make(chan struct{})
+	defer close(block)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithPerRequestTimeout(10*time.Millisecond),
+	)}
+	_, err := c.Get(ts.URL)
+	require.Error(t, err)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	var names []string
+	for _, e := range spans[0].Events() {
+		names = append(names, e.Name)
+	}
+	assert.Contains(t, names, timeoutEvent)
+	assert.Equal(t, label.StringValue(TimeoutSourcePerRequest), spans[0].Attributes()[TimeoutSourceKey])
+}
+
+func TestTransportPerRequestTimeoutBodyRead(t *testing.T) {
+	content := []byte("Hello, world!")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write(content)
+	}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(oteltest.NewTracerProvider()),
+		WithPerRequestTimeout(time.Second),
+	)}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+
+	// The timeout must not be released before the body has been read.
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, content, body)
+	require.NoError(t, res.Body.Close())
+}
+
+func TestTransportPerRequestTimeoutKeepsCallerDeadline(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithPerRequestTimeout(time.Millisecond),
+	)}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue(TimeoutSourceContext), spans[0].Attributes()[TimeoutSourceKey])
+}
+
+func TestTransportTimeoutSourceTransport(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	block := make(chan struct{})
+	defer close(block)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+
+	base := &http.Transport{ResponseHeaderTimeout: 10 * time.Millisecond}
+	defer base.CloseIdleConnections()
+	c := http.Client{Transport: NewTransport(base, WithTracerProvider(provider))}
+	_, err := c.Get(ts.URL)
+	require.Error(t, err)
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.Equal(t, label.StringValue(TimeoutSourceTransport), spans[0].Attributes()[TimeoutSourceKey])
+}
+
+func TestTransportNoTimeoutSource(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(http.DefaultTransport, WithTracerProvider(provider))}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), TimeoutSourceKey)
+}
+
+func TestTransportCacheDebug(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Age", "42")
+		w.Header().Set("X-Cache", "HIT")
+	}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithCacheDebug(true),
+	)}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	attrs := spans[0].Attributes()
+	assert.Equal(t, label.StringValue("42"), attrs[ResponseAgeKey])
+	assert.Equal(t, label.StringValue("HIT"), attrs[ResponseXCacheKey])
+	assert.NotContains(t, attrs, ResponseCacheControlKey)
+}
+
+func TestTransportCapturedResponseTrailers(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		_, _ = w.Write([]byte("body"))
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("Grpc-Message", "OK")
+	}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(
+		http.DefaultTransport,
+		WithTracerProvider(provider),
+		WithCapturedResponseTrailers([]string{"grpc-status", "X-Missing"}),
+	)}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	attrs := spans[0].Attributes()
+	assert.Equal(t, label.StringValue("0"), attrs["http.response.trailer.grpc_status"])
+	assert.NotContains(t, attrs, label.Key("http.response.trailer.grpc_message"))
+	assert.NotContains(t, attrs, label.Key("http.response.trailer.x_missing"))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransportResponseReadStats(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("0123456789")),
+		}, nil
+	})
+	tr := NewTransport(base, WithTracerProvider(provider), WithResponseReadStats(true))
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+
+	// reads of 4, 4, 2 and 0 bytes, the last one returning io.EOF
+	buf := make([]byte, 4)
+	for err == nil {
+		_, err = res.Body.Read(buf)
+	}
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	attrs := spans[0].Attributes()
+	assert.Equal(t, label.Int64Value(4), attrs[ResponseReadCountKey])
+	assert.Equal(t, label.IntValue(4), attrs[ResponseMaxReadSizeKey])
+}
+
+func TestTransportResponseReadStatsDisabled(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer ts.Close()
+
+	c := http.Client{Transport: NewTransport(http.DefaultTransport, WithTracerProvider(provider))}
+	res, err := c.Get(ts.URL)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	assert.NotContains(t, spans[0].Attributes(), ResponseReadCountKey)
+	assert.NotContains(t, spans[0].Attributes(), ResponseMaxReadSizeKey)
+}
+
+// slowBody is a response body whose reads of its second byte onwards block
+// for delay, like a slow server.
+type slowBody struct {
+	io.Reader
+	delay time.Duration
+	reads int
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	if b.reads++; b.reads > 1 {
+		time.Sleep(b.delay)
+	}
+	return b.Reader.Read(p)
+}
+
+func (b *slowBody) Close() error { return nil }
+
+func TestTransportSlowRead(t *testing.T) {
+	sr := new(oteltest.StandardSpanRecorder)
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+	const threshold = 50 * time.Millisecond
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &slowBody{Reader: strings.NewReader("012"), delay: 2 * threshold},
+		}, nil
+	})
+	tr := NewTransport(base, WithTracerProvider(provider), WithSlowReadThreshold(threshold))
+
+	r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	res, err := tr.RoundTrip(r)
+	require.NoError(t, err)
+
+	buf := make([]byte, 1)
+	_, err = res.Body.Read(buf)
+	require.NoError(t, err)
+	// the caller is slow to read
+	time.Sleep(2 * threshold)
+	_, err = res.Body.Read(buf)
+	require.NoError(t, err)
+	// the body is slow to return data, which is not reported
+	_, err = res.Body.Read(buf)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	spans := sr.Completed()
+	require.Len(t, spans, 1)
+	events := spans[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "http.client.slow_read", events[0].Name)
+	gap := events[0].Attributes[SlowReadGapKey]
+	assert.GreaterOrEqual(t, gap.AsInt64(), (2 * threshold).Microseconds())
+}
+
+func TestTransportStreamChunkEvents(t *testing.T) {
+	lines := []string{`{"n":1}` + "\n", `{"n":22}` + "\n", `{"n":333}` + "\n"}
+	next := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range lines {
+			_, _ = io.WriteString(w, line)
+			w.(http.Flusher).Flush()
+			// Send the next line once the client read this one.
+			<-next
+		}
+	}))
+	defer ts.Close()
+
+	for _, enabled := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+		tr := NewTransport(http.DefaultTransport, WithTracerProvider(provider), WithStreamChunkEvents(enabled))
+
+		r, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		require.NoError(t, err)
+		res, err := tr.RoundTrip(r)
+		require.NoError(t, err)
+		buf := make([]byte, 1024)
+		for _, line := range lines {
+			n, err := res.Body.Read(buf)
+			require.NoError(t, err)
+			require.Equal(t, line, string(buf[:n]))
+			next <- struct{}{}
+		}
+		_, err = ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		events := spans[0].Events()
+		if !enabled {
+			assert.Empty(t, events)
+			continue
+		}
+		require.Len(t, events, len(lines))
+		total := 0
+		for i, e := range events {
+			total += len(lines[i])
+			assert.Equal(t, "http.client.response.chunk", e.Name)
+			assert.Equal(t, label.Int64Value(int64(i)), e.Attributes[ResponseChunkIndexKey])
+			assert.Equal(t, label.IntValue(len(lines[i])), e.Attributes[ResponseChunkSizeKey])
+			assert.Equal(t, label.Int64Value(int64(total)), e.Attributes[ResponseChunkTotalKey])
+		}
+	}
+}
+
+func TestTransportOriginatingRoute(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	for _, enabled := range []bool{true, false} {
+		sr := new(oteltest.StandardSpanRecorder)
+		provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+		c := http.Client{Transport: NewTransport(
+			http.DefaultTransport,
+			WithTracerProvider(provider),
+			WithOriginatingRoute(enabled),
+		)}
+		ctx := ContextWithOriginatingRoute(context.Background(), "/users/{id}")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		require.NoError(t, err)
+		res, err := c.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		spans := sr.Completed()
+		require.Len(t, spans, 1)
+		if enabled {
+			assert.Equal(t, label.StringValue("/users/{id}"), spans[0].Attributes()[OriginatingRouteKey])
+		} else {
+			assert.NotContains(t, spans[0].Attributes(), OriginatingRouteKey)
+		}
+	}
+}
+
+func TestTransportProxyAttribute(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("user", "secret")
+
+	// example.invalid is only reachable through the proxy or the fake
+	// RoundTripper.
+	testCases := []struct {
+		name   string
+		base   http.RoundTripper
+		target string
+		want   string
+	}{
+		{
+			name:   "proxied",
+			base:   &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+			target: "http://example.invalid/",
+			want:   proxy.URL,
+		},
+		{
+			name:   "direct",
+			base:   &http.Transport{},
+			target: proxy.URL,
+		},
+		{
+			name:   "not an http.Transport",
+			target: "http://example.invalid/",
+			base: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+			c := http.Client{Transport: NewTransport(tc.base, WithTracerProvider(provider), WithProxyAttribute(true))}
+			res, err := c.Get(tc.target)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			if tc.want == "" {
+				assert.NotContains(t, spans[0].Attributes(), ProxyKey)
+			} else {
+				assert.Equal(t, label.StringValue(tc.want), spans[0].Attributes()[ProxyKey])
+			}
+		})
+	}
+}
+
+func TestTransportErrorBodyCapture(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		body      string
+		want      string
+		truncated bool
+	}{
+		{name: "error", status: http.StatusBadGateway, body: "upstream unavailable", want: "upstream", truncated: true},
+		{name: "short error", status: http.StatusNotFound, body: "missing", want: "missing"},
+		{name: "binary error", status: http.StatusInternalServerError, body: "\xff\xfeok", want: "�ok"},
+		{name: "success", status: http.StatusOK, body: "upstream available"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer ts.Close()
+
+			c := http.Client{Transport: NewTransport(
+				http.DefaultTransport,
+				WithTracerProvider(provider),
+				WithErrorBodyCapture(8),
+			)}
+			res, err := c.Get(ts.URL)
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tc.body, string(body), "the body must be passed through")
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			var events []oteltest.Event
+			for _, e := range spans[0].Events() {
+				if e.Name == errorBodyEvent {
+					events = append(events, e)
+				}
+			}
+			if tc.want == "" {
+				assert.Empty(t, events)
+				return
+			}
+			require.Len(t, events, 1)
+			assert.Equal(t, label.StringValue(tc.want), events[0].Attributes[ResponseBodyKey])
+			assert.Equal(t, label.BoolValue(tc.truncated), events[0].Attributes[ResponseBodyTruncatedKey])
+		})
+	}
+}
+
+func TestTransportReasonPhrase(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		enabled bool
+		status  string
+		want    string
+	}{
+		{name: "custom", enabled: true, status: "429 Slow Down Please", want: "Slow Down Please"},
+		{name: "standard", enabled: true, status: "404 Not Found", want: "Not Found"},
+		{name: "empty", enabled: true, status: "404"},
+		{name: "disabled", status: "404 Not Found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			code, err := strconv.Atoi(tc.status[:3])
+			require.NoError(t, err)
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{Status: tc.status, StatusCode: code, Body: http.NoBody}, nil
+			})
+			tr := NewTransport(base,
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithReasonPhrase(tc.enabled),
+			)
+
+			r, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			if tc.want == "" {
+				assert.NotContains(t, spans[0].Attributes(), ResponseReasonPhraseKey)
+			} else {
+				assert.Equal(t, label.StringValue(tc.want), spans[0].Attributes()[ResponseReasonPhraseKey])
+			}
+		})
+	}
+}
+
+func TestTransportOperationExtractor(t *testing.T) {
+	extractor := func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, "/acme.v1.UserService/") {
+			return r.URL.Path
+		}
+		return ""
+	}
+	for _, tc := range []struct {
+		name      string
+		path      string
+		operation string
+	}{
+		{name: "operation", path: "/acme.v1.UserService/GetUser", operation: "/acme.v1.UserService/GetUser"},
+		{name: "fallback", path: "/healthz"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			meterimpl, meterProvider := oteltest.NewMeterProvider()
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			tr := NewTransport(base,
+				WithTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))),
+				WithMeterProvider(meterProvider),
+				WithOperationExtractor(extractor),
+			)
+
+			r, err := http.NewRequest(http.MethodPost, "http://10.0.0.1:8080"+tc.path, nil)
+			require.NoError(t, err)
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			var measured []oteltest.Measured
+			for _, m := range oteltest.AsStructs(meterimpl.MeasurementBatches) {
+				if m.Name == clientRequestDuration {
+					measured = append(measured, m)
+				}
+			}
+			require.Len(t, measured, 1)
+			labels := measured[0].Labels
+
+			if tc.operation == "" {
+				assert.Equal(t, http.MethodPost, spans[0].Name())
+				assert.NotContains(t, spans[0].Attributes(), OperationKey)
+				assert.Equal(t, label.StringValue("10.0.0.1:8080"), labels[semconv.HTTPHostKey])
+				assert.NotContains(t, labels, OperationKey)
+				return
+			}
+			assert.Equal(t, tc.operation, spans[0].Name())
+			assert.Equal(t, label.StringValue(tc.operation), spans[0].Attributes()[OperationKey])
+			assert.Equal(t, label.StringValue(tc.operation), labels[OperationKey])
+			assert.NotContains(t, labels, semconv.HTTPHostKey)
+			assert.NotContains(t, labels, semconv.HTTPURLKey)
+			assert.Equal(t, label.StringValue(http.MethodPost), labels[semconv.HTTPMethodKey])
+		})
+	}
+}
+
+func TestTransportHostHeader(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		host string
+		want bool
+	}{
+		{name: "unset"},
+		{name: "same", host: "example.com:8080"},
+		{name: "rewritten", host: "api.example.com", want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			tr := NewTransport(base, WithTracerProvider(provider))
+
+			r, err := http.NewRequest(http.MethodGet, "http://example.com:8080/", nil)
+			require.NoError(t, err)
+			r.Host = tc.host
+			res, err := tr.RoundTrip(r)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			attrs := spans[0].Attributes()
+			if !tc.want {
+				assert.NotContains(t, attrs, ServerAddressKey)
+				assert.NotContains(t, attrs, RequestHostKey)
+				return
+			}
+			assert.Equal(t, label.StringValue("example.com:8080"), attrs[ServerAddressKey])
+			assert.Equal(t, label.StringValue("api.example.com"), attrs[RequestHostKey])
+		})
+	}
+}
+
+func TestTransportAbsoluteTimestamps(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		enabled     bool
+		err         error
+		wantReceive bool
+	}{
+		{name: "disabled"},
+		{name: "enabled", enabled: true, wantReceive: true},
+		{name: "failed", enabled: true, err: errors.New("connection refused")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sr := new(oteltest.StandardSpanRecorder)
+			provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(sr))
+			var handled time.Time
+			base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				time.Sleep(time.Millisecond)
+				handled = time.Now()
+				time.Sleep(time.Millisecond)
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			tr := NewTransport(base, WithTracerProvider(provider), WithAbsoluteTimestamps(tc.enabled))
+
+			r, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			require.NoError(t, err)
+			if res, err := tr.RoundTrip(r); err == nil {
+				require.NoError(t, res.Body.Close())
+			}
+
+			spans := sr.Completed()
+			require.Len(t, spans, 1)
+			attrs := spans[0].Attributes()
+			if !tc.enabled {
+				assert.NotContains(t, attrs, RequestSendTimeKey)
+				assert.NotContains(t, attrs, ResponseReceiveTimeKey)
+				return
+			}
+			require.Contains(t, attrs, RequestSendTimeKey)
+			assert.Less(t, attrs[RequestSendTimeKey].AsInt64(), handled.UnixNano())
+			if !tc.wantReceive {
+				assert.NotContains(t, attrs, ResponseReceiveTimeKey)
+				return
+			}
+			require.Contains(t, attrs, ResponseReceiveTimeKey)
+			assert.Greater(t, attrs[ResponseReceiveTimeKey].AsInt64(), handled.UnixNano())
+		})
+	}
+}
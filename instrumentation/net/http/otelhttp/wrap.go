@@ -18,6 +18,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"time"
 
 	"go.opentelemetry.io/otel/propagation"
 )
@@ -25,28 +26,88 @@ import (
 var _ io.ReadCloser = &bodyWrapper{}
 
 // bodyWrapper wraps a http.Request.Body (an io.ReadCloser) to track the number
-// of bytes read and the last error
+// of bytes read, the last error and when the end of the body was reached
 type bodyWrapper struct {
 	io.ReadCloser
 	record func(n int64) // must not be nil
 
 	read int64
 	err  error
+	eof  time.Time // zero until io.EOF is read
+
+	// stallThreshold is the duration above which a read is a stall, reported
+	// to stalled, if positive.
+	stallThreshold time.Duration
+	stalled        func(d time.Duration)
+	stalls         int64
+
+	tee func([]byte) // passed the bytes read, if WithBodyTee is used
 }
 
 func (w *bodyWrapper) Read(b []byte) (int, error) {
+	var start time.Time
+	if w.stallThreshold > 0 {
+		start = time.Now()
+	}
 	n, err := w.ReadCloser.Read(b)
+	if w.tee != nil {
+		w.tee(b[:n])
+	}
+	if w.stallThreshold > 0 {
+		if d := time.Since(start); d > w.stallThreshold {
+			w.stalls++
+			w.stalled(d)
+		}
+	}
 	n1 := int64(n)
 	w.read += n1
-	w.err = err
+	w.setErr(err)
 	w.record(n1)
 	return n, err
 }
 
+func (w *bodyWrapper) setErr(err error) {
+	w.err = err
+	if err == io.EOF && w.eof.IsZero() {
+		w.eof = time.Now()
+	}
+}
+
 func (w *bodyWrapper) Close() error {
 	return w.ReadCloser.Close()
 }
 
+// bodyWriterToWrapper is a bodyWrapper for bodies implementing io.WriterTo,
+// so that io.Copy keeps using their WriteTo method.
+type bodyWriterToWrapper struct {
+	*bodyWrapper
+}
+
+func (w bodyWriterToWrapper) WriteTo(dst io.Writer) (int64, error) {
+	if w.tee != nil {
+		dst = teeWriter{w: dst, tee: w.tee}
+	}
+	n, err := w.ReadCloser.(io.WriterTo).WriteTo(dst)
+	w.read += n
+	if err == nil {
+		// WriteTo reads until EOF, which it does not report.
+		w.setErr(io.EOF)
+	} else {
+		w.setErr(err)
+	}
+	w.record(n)
+	return n, err
+}
+
+// wrap returns the body to replace the wrapped one with, which implements
+// the same of the optional interfaces, like io.WriterTo, as the wrapped one.
+func (w *bodyWrapper) wrap() io.ReadCloser {
+	if _, ok := w.ReadCloser.(io.WriterTo); ok {
+		return bodyWriterToWrapper{w}
+	}
+	return w
+}
+
 var _ http.ResponseWriter = &respWriterWrapper{}
 
 // respWriterWrapper wraps a http.ResponseWriter in order to track the number of
@@ -67,6 +128,13 @@ type respWriterWrapper struct {
 	statusCode  int
 	err         error
 	wroteHeader bool
+
+	tee func([]byte) // passed the bytes written, if WithBodyTee is used
+
+	timeWrites    bool          // whether to sum the duration of writes, if WithResponseWriteDuration is used
+	writeDuration time.Duration // the summed duration of writes to the ResponseWriter
+
+	hijacked bool // whether the connection was hijacked, only tracked if WithHandlerDuration is used
 }
 
 func (w *respWriterWrapper) Header() http.Header {
@@ -77,7 +145,17 @@ func (w *respWriterWrapper) Write(p []byte) (int, error) {
 	if !w.wroteHeader {
 		w.WriteHeader(http.StatusOK)
 	}
+	var start time.Time
+	if w.timeWrites {
+		start = time.Now()
+	}
 	n, err := w.ResponseWriter.Write(p)
+	if w.timeWrites {
+		w.writeDuration += time.Since(start)
+	}
+	if w.tee != nil {
+		w.tee(p[:n])
+	}
 	n1 := int64(n)
 	w.record(n1)
 	w.written += n1
@@ -92,5 +170,11 @@ func (w *respWriterWrapper) WriteHeader(statusCode int) {
 	w.wroteHeader = true
 	w.statusCode = statusCode
 	w.props.Inject(w.ctx, w.Header())
+	if !w.timeWrites {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	start := time.Now()
 	w.ResponseWriter.WriteHeader(statusCode)
+	w.writeDuration += time.Since(start)
 }
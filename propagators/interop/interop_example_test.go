@@ -0,0 +1,33 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interop_test
+
+import (
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/interop"
+	"go.opentelemetry.io/otel"
+)
+
+func ExampleInterop() {
+	// Extract from W3C Trace Context or B3 headers, emit W3C Trace Context.
+	otel.SetTextMapPropagator(interop.Interop{})
+}
+
+func ExampleInterop_emit() {
+	// Extract from W3C Trace Context or B3 headers, emit B3 single headers.
+	otel.SetTextMapPropagator(interop.Interop{
+		Emit: b3.B3{InjectEncoding: b3.B3SingleHeader},
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interop
+
+import (
+	"context"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Interop propagator extracts a SpanContext from W3C Trace Context headers
+// if a request carries a valid traceparent header, and from B3 single or
+// multiple headers otherwise. The SpanContext is injected with the Emit
+// propagator only, so that outbound requests carry one format regardless
+// of the format of the inbound request.
+//
+// It is meant to be passed to the WithPropagators option of the server
+// instrumentation, like otelhttp.NewHandler or otelrestful.OTelFilter, and
+// of the client instrumentation of the service.
+type Interop struct {
+	// Emit is the propagator used when injecting trace information. If
+	// nil, W3C Trace Context is used:
+	//
+	//	interop.Interop{Emit: b3.B3{InjectEncoding: b3.B3SingleHeader}}
+	//
+	// emits B3 single headers instead.
+	Emit propagation.TextMapPropagator
+}
+
+var _ propagation.TextMapPropagator = Interop{}
+
+// Inject injects a context into the carrier with the Emit propagator.
+func (i Interop) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	i.emit().Inject(ctx, carrier)
+}
+
+// Extract extracts a context from the carrier if it contains W3C Trace
+// Context or B3 headers. W3C Trace Context takes precedence if the carrier
+// contains both.
+func (i Interop) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	tc := propagation.TraceContext{}
+	if trace.RemoteSpanContextFromContext(tc.Extract(context.Background(), carrier)).IsValid() {
+		return tc.Extract(ctx, carrier)
+	}
+	return b3.B3{}.Extract(ctx, carrier)
+}
+
+// Fields returns the keys whose values are set with Inject.
+func (i Interop) Fields() []string {
+	return i.emit().Fields()
+}
+
+func (i Interop) emit() propagation.TextMapPropagator {
+	if i.Emit == nil {
+		return propagation.TraceContext{}
+	}
+	return i.Emit
+}
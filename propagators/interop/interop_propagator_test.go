@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interop_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/interop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	traceID  = trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID   = trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	traceID2 = trace.TraceID{0x80, 0xf1, 0x98, 0xee, 0x56, 0x34, 0x3b, 0xa8, 0x64, 0xfe, 0x8b, 0x2a, 0x57, 0xd3, 0xef, 0xf7}
+	spanID2  = trace.SpanID{0xe4, 0x57, 0xb5, 0xa2, 0xe4, 0xd8, 0x6b, 0xd1}
+)
+
+type testSpan struct {
+	trace.Span
+	sc trace.SpanContext
+}
+
+func (s testSpan) SpanContext() trace.SpanContext {
+	return s.sc
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantSc  trace.SpanContext
+	}{
+		{
+			name: "w3c",
+			headers: map[string]string{
+				"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			wantSc: trace.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled},
+		},
+		{
+			name: "b3 single header",
+			headers: map[string]string{
+				"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+			},
+			wantSc: trace.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled},
+		},
+		{
+			name: "b3 multiple header",
+			headers: map[string]string{
+				"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"x-b3-spanid":  "00f067aa0ba902b7",
+				"x-b3-sampled": "0",
+			},
+			wantSc: trace.SpanContext{TraceID: traceID, SpanID: spanID},
+		},
+		{
+			name: "w3c takes precedence",
+			headers: map[string]string{
+				"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"b3":          "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+			},
+			wantSc: trace.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled},
+		},
+		{
+			name: "invalid w3c falls back to b3",
+			headers: map[string]string{
+				"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"b3":          "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1",
+			},
+			wantSc: trace.SpanContext{TraceID: traceID2, SpanID: spanID2, TraceFlags: trace.FlagsSampled},
+		},
+		{
+			name:    "none",
+			headers: map[string]string{},
+			wantSc:  trace.SpanContext{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			for h, v := range tt.headers {
+				req.Header.Set(h, v)
+			}
+
+			ctx := interop.Interop{}.Extract(context.Background(), req.Header)
+			gotSc := trace.RemoteSpanContextFromContext(ctx)
+			if diff := cmp.Diff(gotSc, tt.wantSc); diff != "" {
+				t.Errorf("-got +want %s", diff)
+			}
+		})
+	}
+}
+
+func TestInject(t *testing.T) {
+	sc := trace.SpanContext{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled}
+	ctx := trace.ContextWithSpan(context.Background(), testSpan{sc: sc})
+
+	tests := []struct {
+		name        string
+		propagator  interop.Interop
+		wantHeaders map[string]string
+		wantFields  []string
+	}{
+		{
+			name:       "default",
+			propagator: interop.Interop{},
+			wantHeaders: map[string]string{
+				"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			wantFields: []string{"traceparent", "tracestate"},
+		},
+		{
+			name:       "b3 single header",
+			propagator: interop.Interop{Emit: b3.B3{InjectEncoding: b3.B3SingleHeader}},
+			wantHeaders: map[string]string{
+				"b3": "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+			},
+			wantFields: []string{"b3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			tt.propagator.Inject(ctx, header)
+
+			got := map[string]string{}
+			for h := range header {
+				got[http.CanonicalHeaderKey(h)] = header.Get(h)
+			}
+			want := map[string]string{}
+			for h, v := range tt.wantHeaders {
+				want[http.CanonicalHeaderKey(h)] = v
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("headers: -got +want %s", diff)
+			}
+			if diff := cmp.Diff(tt.propagator.Fields(), tt.wantFields); diff != "" {
+				t.Errorf("fields: -got +want %s", diff)
+			}
+		})
+	}
+}